@@ -0,0 +1,50 @@
+package revel
+
+import (
+	"sort"
+	"strings"
+)
+
+// Add a Link header with rel="alternate" for each language variant of the page,
+// so that crawlers may find them.  The alternates map each locale (e.g.
+// "en-US") to the URL of its variant.  The variant of the default locale is
+// also linked as hreflang="x-default", unless the map has an "x-default" entry
+// of its own.
+//
+// The links are added in order of locale, with x-default last.  URLs that would
+// break the header (containing "<", ">" or control characters) are skipped.
+func (resp *Response) SetAlternateLanguages(alternates map[string]string, defaultLocale string) {
+	locales := make([]string, 0, len(alternates))
+	for locale := range alternates {
+		if locale != "x-default" {
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales)
+
+	defaultURL, ok := alternates["x-default"]
+	if !ok {
+		defaultURL, ok = alternates[defaultLocale]
+	}
+	if ok {
+		locales = append(locales, "x-default")
+	}
+
+	header := resp.Out.Header()
+	for _, locale := range locales {
+		url := alternates[locale]
+		if locale == "x-default" {
+			url = defaultURL
+		}
+		if url == "" || strings.ContainsAny(url, "<>") || strings.IndexFunc(url, isControl) != -1 {
+			WARN.Printf("Skipping the alternate link for '%s' to the invalid URL '%s'", locale, url)
+			continue
+		}
+		header.Add("Link", "<"+url+`>; rel="alternate"; hreflang=`+quoteHeaderString(locale))
+	}
+}
+
+// Return true if the character is an ASCII control character.
+func isControl(c rune) bool {
+	return c < 0x20 || c == 0x7f
+}
@@ -0,0 +1,38 @@
+package revel
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSetAlternateLanguages(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	NewResponse(recorder).SetAlternateLanguages(map[string]string{
+		"nl":    "https://example.com/nl/",
+		"en-US": "https://example.com/en/",
+		"fr":    "https://example.com/<fr>",
+	}, "en-US")
+
+	expected := []string{
+		`<https://example.com/en/>; rel="alternate"; hreflang="en-US"`,
+		`<https://example.com/nl/>; rel="alternate"; hreflang="nl"`,
+		`<https://example.com/en/>; rel="alternate"; hreflang="x-default"`,
+	}
+	if actual := recorder.Header()["Link"]; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+
+	recorder = httptest.NewRecorder()
+	NewResponse(recorder).SetAlternateLanguages(map[string]string{
+		"de":        "/de/",
+		"x-default": "/choose-language",
+	}, "de")
+	expected = []string{
+		`</de/>; rel="alternate"; hreflang="de"`,
+		`</choose-language>; rel="alternate"; hreflang="x-default"`,
+	}
+	if actual := recorder.Header()["Link"]; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
@@ -0,0 +1,150 @@
+package revel
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// The b64token syntax of bearer tokens. (RFC 6750 section 2.1)
+var bearerTokenPattern = regexp.MustCompile(`^[A-Za-z0-9\-._~+/]+=*$`)
+
+// Return the bearer token from the Authorization header.
+// ok is false if there is no bearer token, or if it is malformed.
+func (r *Request) BearerToken() (token string, ok bool) {
+	authorization := r.Header.Get("Authorization")
+	if len(authorization) < 7 || !strings.EqualFold(authorization[:7], "Bearer ") {
+		return "", false
+	}
+	token = authorization[7:]
+	if !bearerTokenPattern.MatchString(token) {
+		return "", false
+	}
+	return token, true
+}
+
+// Return the scheme and auth-params of the Authorization header, for schemes
+// whose credentials are a list of name=value parameters (e.g. Digest).
+// Parameter names are lower-cased, and quoted values are unquoted.
+// ok is false if there is no Authorization header, or if its credentials are
+// not a parameter list (e.g. the token68 of Basic or Bearer).
+func (r *Request) AuthorizationParams() (scheme string, params map[string]string, ok bool) {
+	authorization := strings.TrimSpace(r.Header.Get("Authorization"))
+	scheme, credentials := authorization, ""
+	if i := strings.IndexAny(authorization, " \t"); i != -1 {
+		scheme, credentials = authorization[:i], authorization[i+1:]
+	}
+	if scheme == "" || tokenLength(scheme) != len(scheme) {
+		return "", nil, false
+	}
+	if params, ok = parseAuthParams(credentials); !ok {
+		return "", nil, false
+	}
+	return scheme, params, true
+}
+
+// Parse a comma-separated list of auth-params: token = ( token / quoted-string ).
+func parseAuthParams(s string) (map[string]string, bool) {
+	params := make(map[string]string)
+	for i := 0; ; {
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == ',') {
+			i++
+		}
+		if i == len(s) {
+			return params, true
+		}
+
+		n := tokenLength(s[i:])
+		if n == 0 {
+			return nil, false
+		}
+		name := strings.ToLower(s[i : i+n])
+		i = skipSpace(s, i+n)
+		if i == len(s) || s[i] != '=' {
+			return nil, false
+		}
+		i = skipSpace(s, i+1)
+
+		var value string
+		if i < len(s) && s[i] == '"' {
+			var quoted []byte
+			for i++; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				quoted = append(quoted, s[i])
+			}
+			if i == len(s) {
+				return nil, false
+			}
+			value, i = string(quoted), i+1
+		} else {
+			if n = tokenLength(s[i:]); n == 0 {
+				return nil, false
+			}
+			value, i = s[i:i+n], i+n
+		}
+		params[name] = value
+
+		i = skipSpace(s, i)
+		if i < len(s) && s[i] != ',' {
+			return nil, false
+		}
+	}
+}
+
+// Return the length of the HTTP token at the start of s.
+func tokenLength(s string) int {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+			strings.IndexByte("!#$%&'*+-.^_`|~", c) != -1) {
+			return i
+		}
+	}
+	return len(s)
+}
+
+// Return the index of the first character at or after i that is not a space.
+func skipSpace(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+		i++
+	}
+	return i
+}
+
+// Write a WWW-Authenticate: Bearer challenge. (RFC 6750 section 3)
+//
+// errorCode is empty if the request had no credentials, or else one of
+// "invalid_request" (written as a 400), "invalid_token" (401) or
+// "insufficient_scope" (403).  The realm and description are optional.
+func (resp *Response) RequireBearer(req *Request, realm, errorCode, errorDescription string) {
+	var params []string
+	if realm != "" {
+		params = append(params, `realm=`+quoteHeaderString(realm))
+	}
+	if errorCode != "" {
+		params = append(params, `error=`+quoteHeaderString(errorCode))
+	}
+	if errorDescription != "" {
+		params = append(params, `error_description=`+quoteHeaderString(errorDescription))
+	}
+	challenge := "Bearer"
+	if len(params) > 0 {
+		challenge += " " + strings.Join(params, ", ")
+	}
+	resp.Out.Header().Set("WWW-Authenticate", challenge)
+
+	status := http.StatusUnauthorized
+	switch errorCode {
+	case "invalid_request":
+		status = http.StatusBadRequest
+	case "insufficient_scope":
+		status = http.StatusForbidden
+	}
+	description := errorDescription
+	if description == "" {
+		description = "A valid bearer token is required."
+	}
+	resp.writeStatusError(req, status, http.StatusText(status), description)
+}
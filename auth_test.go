@@ -0,0 +1,73 @@
+package revel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestBearerToken(t *testing.T) {
+	testCases := map[string]string{
+		"Bearer mF_9.B5f-4.1JqM":         "mF_9.B5f-4.1JqM",
+		"bearer abc==":                   "abc==",
+		"Bearer  abc":                    "",
+		"Bearer a=bc":                    "",
+		"Bearer ":                        "",
+		"Basic YWxhZGRpbjpvcGVuc2VzYW1l": "",
+		"":                               "",
+	}
+	for authorization, expected := range testCases {
+		httpRequest, _ := http.NewRequest("GET", "/", nil)
+		httpRequest.Header.Set("Authorization", authorization)
+		token, ok := NewRequest(httpRequest).BearerToken()
+		if token != expected || ok != (expected != "") {
+			t.Errorf("%q: expected %q, got %q (%v)", authorization, expected, token, ok)
+		}
+	}
+}
+
+func TestRequireBearer(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	NewResponse(recorder).RequireBearer(newTestRequest("json"), "example", "invalid_token", `The "token" expired`)
+	expected := `Bearer realm="example", error="invalid_token", error_description="The \"token\" expired"`
+	if actual := recorder.Header().Get("WWW-Authenticate"); actual != expected {
+		t.Errorf("Expected %s, got %s", expected, actual)
+	}
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a 401, got %d", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	NewResponse(recorder).RequireBearer(newTestRequest("json"), "", "insufficient_scope", "")
+	if actual := recorder.Header().Get("WWW-Authenticate"); actual != `Bearer error="insufficient_scope"` || recorder.Code != http.StatusForbidden {
+		t.Errorf("Expected a 403 insufficient_scope challenge, got %d %s", recorder.Code, actual)
+	}
+}
+
+func TestAuthorizationParams(t *testing.T) {
+	testCases := []struct {
+		authorization, scheme string
+		params                map[string]string
+		ok                    bool
+	}{
+		{`Digest username="Mufasa", realm="a, \"quoted\" realm",nc=00000001 , qop=auth`, "Digest",
+			map[string]string{"username": "Mufasa", "realm": `a, "quoted" realm`, "nc": "00000001", "qop": "auth"}, true},
+		{`HOBA Result = abc.def`, "HOBA", map[string]string{"result": "abc.def"}, true},
+		{`Custom`, "Custom", map[string]string{}, true},
+		{`Basic dXNlcjpwYXNz`, "", nil, false},
+		{`Bearer abc==`, "", nil, false},
+		{`Digest username="unterminated`, "", nil, false},
+		{`Digest a=b c=d`, "", nil, false},
+		{``, "", nil, false},
+	}
+	for _, testCase := range testCases {
+		httpRequest, _ := http.NewRequest("GET", "/", nil)
+		httpRequest.Header.Set("Authorization", testCase.authorization)
+		scheme, params, ok := NewRequest(httpRequest).AuthorizationParams()
+		if scheme != testCase.scheme || ok != testCase.ok || !reflect.DeepEqual(params, testCase.params) {
+			t.Errorf("%q: expected %q %v %v, got %q %v %v", testCase.authorization,
+				testCase.scheme, testCase.params, testCase.ok, scheme, params, ok)
+		}
+	}
+}
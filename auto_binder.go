@@ -0,0 +1,91 @@
+package revel
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+)
+
+// Where Request.BindAuto takes the values to bind from.
+type BindSource int
+
+const (
+	BindFromMethod BindSource = iota // The query or the body, depending on the method.
+	BindFromQuery                    // The query string.
+	BindFromBody                     // The body, decoded by its Content-Type.
+)
+
+// The media types of the bodies that Request.BindAuto can decode.
+var bindBodyMediaTypes = []string{"application/json", "application/x-www-form-urlencoded", "multipart/form-data"}
+
+// Bind the request data to the fields of the struct pointed to by v, taking it
+// from wherever the request method puts it:
+//   - GET, HEAD, DELETE (and other methods): the query string, as BindQuery
+//   - POST, PUT, PATCH: the body, by its Content-Type.  JSON is decoded as by
+//     ReadJSON, and urlencoded and multipart forms are bound as by BindQuery
+//     (from the body's form values only, see FormMap).  Other types result in a
+//     *UnsupportedMediaTypeError (415).  An empty body binds nothing.
+//
+// The source may be chosen explicitly instead, e.g. to bind a DELETE body:
+//
+//     err := c.Request.BindAuto(&item, revel.BindFromBody)
+//
+// Finally, the path parameters of the matched route (e.g. the id of
+// /items/{id}) are bound as by BindQuery, so that they take precedence over
+// values of the same name in the query or body.
+func (r *Request) BindAuto(v interface{}, source ...BindSource) error {
+	ptr := reflect.ValueOf(v)
+	if ptr.Kind() != reflect.Ptr || ptr.Elem().Kind() != reflect.Struct {
+		return errors.New("BindAuto requires a pointer to a struct")
+	}
+
+	from := BindFromMethod
+	if len(source) > 0 {
+		from = source[0]
+	}
+	if from == BindFromMethod {
+		from = BindFromQuery
+		switch r.Method {
+		case "POST", "PUT", "PATCH":
+			from = BindFromBody
+		}
+	}
+
+	var err error
+	if from == BindFromBody {
+		err = r.bindBody(v)
+	} else {
+		err = bindValues(r.URL.Query(), ptr.Elem())
+	}
+	if err != nil || len(r.routeParams) == 0 {
+		return err
+	}
+
+	pathValues := make(url.Values, len(r.routeParams))
+	for key, value := range r.routeParams {
+		pathValues.Set(key, value)
+	}
+	return bindValues(pathValues, ptr.Elem())
+}
+
+// Bind the request body to v, by its Content-Type.
+func (r *Request) bindBody(v interface{}) error {
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 && len(r.TransferEncoding) == 0 {
+		return nil
+	}
+	switch {
+	case isJSONMediaType(r.ContentType):
+		return r.ReadJSON(v)
+	case r.ContentType == "application/x-www-form-urlencoded", r.ContentType == "multipart/form-data":
+		values, err := r.bodyForm()
+		if err != nil {
+			return err
+		}
+		return bindValues(values, reflect.ValueOf(v).Elem())
+	}
+	if r.Header.Get("Content-Type") == "" {
+		return &UnsupportedMediaTypeError{"", bindBodyMediaTypes}
+	}
+	return &UnsupportedMediaTypeError{r.ContentType, bindBodyMediaTypes}
+}
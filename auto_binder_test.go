@@ -0,0 +1,70 @@
+package revel
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type autoItem struct {
+	ID   int    `query:"id" json:"id"`
+	Name string `json:"name"`
+	Tags []string
+}
+
+func TestBindAuto(t *testing.T) {
+	testCases := []struct {
+		method, url, contentType, body string
+		routeParams                    map[string]string
+		source                         []BindSource
+		expected                       autoItem
+	}{
+		{"GET", "/items?name=a&tags=x&tags=y", "", "", nil, nil, autoItem{0, "a", []string{"x", "y"}}},
+		{"DELETE", "/items/3?id=4&name=a", "application/json", `{"name":"b"}`, map[string]string{"id": "3"}, nil, autoItem{3, "a", nil}},
+		{"POST", "/items?name=q", "application/json", `{"id":1,"name":"b","Tags":["z"]}`, nil, nil, autoItem{1, "b", []string{"z"}}},
+		{"PUT", "/items/2", "application/json", `{"id":1,"name":"b"}`, map[string]string{"id": "2"}, nil, autoItem{2, "b", nil}},
+		{"PATCH", "/items?name=q", "application/x-www-form-urlencoded", "name=b&tags=x", nil, nil, autoItem{0, "b", []string{"x"}}},
+		{"PATCH", "/items?id=5&tags=q", "application/x-www-form-urlencoded", "tags=x&tags=y", nil, nil, autoItem{0, "", []string{"x", "y"}}},
+		{"POST", "/items?id=5&tags=q", "multipart/form-data; boundary=b", "--b\r\nContent-Disposition: form-data; name=\"tags\"\r\n\r\nx\r\n--b--\r\n",
+			nil, nil, autoItem{0, "", []string{"x"}}},
+		{"POST", "/items?name=q", "", "", nil, nil, autoItem{}},
+		{"POST", "/items?name=q", "application/json", `{"name":"b"}`, nil, []BindSource{BindFromQuery}, autoItem{0, "q", nil}},
+		{"DELETE", "/items?name=q", "application/json", `{"name":"b"}`, nil, []BindSource{BindFromBody}, autoItem{0, "b", nil}},
+	}
+	for _, test := range testCases {
+		httpRequest, _ := http.NewRequest(test.method, test.url, strings.NewReader(test.body))
+		if test.contentType != "" {
+			httpRequest.Header.Set("Content-Type", test.contentType)
+		}
+		req := NewRequest(httpRequest)
+		req.routeParams = test.routeParams
+		var item autoItem
+		if err := req.BindAuto(&item, test.source...); err != nil {
+			t.Errorf("%s %s: unexpected error: %s", test.method, test.url, err)
+		} else if !reflect.DeepEqual(item, test.expected) {
+			t.Errorf("%s %s: expected %+v, got %+v", test.method, test.url, test.expected, item)
+		}
+	}
+
+	httpRequest, _ := http.NewRequest("POST", "/items", strings.NewReader("<item/>"))
+	httpRequest.Header.Set("Content-Type", "application/xml")
+	err := NewRequest(httpRequest).BindAuto(&autoItem{})
+	var mediaTypeErr *UnsupportedMediaTypeError
+	if !errors.As(err, &mediaTypeErr) || mediaTypeErr.ContentType != "application/xml" {
+		t.Errorf("Expected an UnsupportedMediaTypeError, got %v", err)
+	}
+
+	httpRequest, _ = http.NewRequest("GET", "/items/x", nil)
+	req := NewRequest(httpRequest)
+	req.routeParams = map[string]string{"id": "x"}
+	var bindErr *QueryBindError
+	if err := req.BindAuto(&autoItem{}); !errors.As(err, &bindErr) || bindErr.Param != "id" {
+		t.Errorf("Expected an error for id, got %v", err)
+	}
+
+	if err := req.BindAuto(autoItem{}); err == nil {
+		t.Errorf("Expected an error binding to a non-pointer")
+	}
+}
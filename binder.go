@@ -25,6 +25,10 @@ import (
 //   Binder(params, "ul", []string): {"str", "array"}
 //   Binder(params, "user", User): User{Name:"rob"}
 //
+// Struct fields and map keys may also be given in brackets, as emitted by many
+// front-end libraries.  e.g. user[name]=rob, tags[color]=red
+// Bracketed field names are matched case-insensitively.
+//
 // Note that only exported struct fields may be bound.
 type Binder func(params *Params, name string, typ reflect.Type) reflect.Value
 
@@ -56,6 +60,10 @@ var (
 
 	DateFormat     string
 	DateTimeFormat string
+
+	// The deepest level of nesting (e.g. a[b][c].d is 3) that will be bound.
+	// Parameters nested further are ignored, to limit the work a request can cause.
+	MaxBindDepth = 16
 )
 
 // Sadly, the binder lookups can not be declared initialized -- that results in
@@ -80,6 +88,7 @@ func init() {
 	KindBinders[reflect.Bool] = ValueBinder(bindBool)
 	KindBinders[reflect.Slice] = bindSlice
 	KindBinders[reflect.Struct] = bindStruct
+	KindBinders[reflect.Map] = bindMap
 	KindBinders[reflect.Ptr] = bindPointer
 
 	TypeBinders[reflect.TypeOf(time.Time{})] = ValueBinder(bindTime)
@@ -279,35 +288,97 @@ func nextKey(key string) string {
 	return key[:fieldLen]
 }
 
+// Return the sub-key of the given key that follows the name, along with the end
+// of that sub-key in the key.  The sub-key may follow a dot or be in brackets.
+// e.g. ("user.Name.First", "user") => ("Name", 9); ("user[name]", "user") => ("name", 10)
+func subKey(key, name string) (string, int) {
+	if !strings.HasPrefix(key, name) || len(key) <= len(name)+1 {
+		return "", -1
+	}
+	switch key[len(name)] {
+	case '.':
+		field := nextKey(key[len(name)+1:])
+		return field, len(name) + 1 + len(field)
+	case '[':
+		rightBracket := strings.Index(key[len(name):], "]")
+		if rightBracket == -1 {
+			return "", -1
+		}
+		return key[len(name)+1 : len(name)+rightBracket], len(name) + rightBracket + 1
+	}
+	return "", -1
+}
+
+// Return the nesting depth of the parameter name.
+func bindDepth(name string) int {
+	return strings.Count(name, ".") + strings.Count(name, "[")
+}
+
 func bindStruct(params *Params, name string, typ reflect.Type) reflect.Value {
 	result := reflect.New(typ).Elem()
+	if bindDepth(name) >= MaxBindDepth {
+		WARN.Println("W: bindStruct: Parameter nested too deeply:", name)
+		return result
+	}
+
 	fieldValues := make(map[string]reflect.Value)
 	for key, _ := range params.Values {
-		if !strings.HasPrefix(key, name+".") {
+		// Get the name of the struct property.
+		// e.g. (foo.bar.baz or foo[bar].baz) => bar
+		fieldName, fieldEnd := subKey(key, name)
+		if fieldName == "" {
 			continue
 		}
 
-		// Get the name of the struct property.
-		// Strip off the prefix. e.g. foo.bar.baz => bar.baz
-		suffix := key[len(name)+1:]
-		fieldName := nextKey(suffix)
-		fieldLen := len(fieldName)
+		// Find the field, falling back to a case-insensitive match.
+		structField, ok := typ.FieldByName(fieldName)
+		if !ok {
+			structField, ok = typ.FieldByNameFunc(func(n string) bool {
+				return strings.EqualFold(n, fieldName)
+			})
+		}
+		if !ok {
+			WARN.Println("W: bindStruct: Field not found:", fieldName)
+			continue
+		}
 
-		if _, ok := fieldValues[fieldName]; !ok {
+		if _, ok := fieldValues[structField.Name]; !ok {
 			// Time to bind this field.  Get it and make sure we can set it.
-			fieldValue := result.FieldByName(fieldName)
-			if !fieldValue.IsValid() {
-				WARN.Println("W: bindStruct: Field not found:", fieldName)
-				continue
-			}
+			fieldValue := result.FieldByIndex(structField.Index)
 			if !fieldValue.CanSet() {
 				WARN.Println("W: bindStruct: Field not settable:", fieldName)
 				continue
 			}
-			boundVal := Bind(params, key[:len(name)+1+fieldLen], fieldValue.Type())
+			boundVal := Bind(params, key[:fieldEnd], fieldValue.Type())
 			fieldValue.Set(boundVal)
-			fieldValues[fieldName] = boundVal
+			fieldValues[structField.Name] = boundVal
+		}
+	}
+
+	return result
+}
+
+// Maps are bound from bracketed keys: name[key]=value
+func bindMap(params *Params, name string, typ reflect.Type) reflect.Value {
+	result := reflect.MakeMap(typ)
+	if bindDepth(name) >= MaxBindDepth {
+		WARN.Println("W: bindMap: Parameter nested too deeply:", name)
+		return result
+	}
+
+	for key, _ := range params.Values {
+		if !strings.HasPrefix(key, name+"[") {
+			continue
+		}
+		mapKey, keyEnd := subKey(key, name)
+		if mapKey == "" {
+			continue
+		}
+		keyValue := BindValue(mapKey, typ.Key())
+		if result.MapIndex(keyValue).IsValid() {
+			continue
 		}
+		result.SetMapIndex(keyValue, Bind(params, key[:keyEnd], typ.Elem()))
 	}
 
 	return result
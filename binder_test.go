@@ -70,6 +70,16 @@ var (
 		"arrC[0].B.Extra": {"foo"},
 		"arrC[1].Id":      {"8"},
 		"arrC[1].Name":    {"bill"},
+		"C[id]":           {"5"},
+		"C[Name]":         {"rob"},
+		"C[b][extra]":     {"foo"},
+		"D[B].Extra":      {"bar"},
+		"mixarr[0]":       {"1"},
+		"mixarr[]":        {"2"},
+		"map[a]":          {"1"},
+		"map[b]":          {"2"},
+		"mapC[x][Id]":     {"3"},
+		"mapC[x].B.Extra": {"baz"},
 		"invalidInt":      {"xyz"},
 		"invalidInt2":     {""},
 		"invalidBool":     {"xyz"},
@@ -122,6 +132,12 @@ var binderTestCases = map[string]interface{}{
 		},
 	},
 
+	"C":      A{Id: 5, Name: "rob", B: B{"foo"}},
+	"D":      A{B: B{"bar"}},
+	"mixarr": []int{1, 2},
+	"map":    map[string]int{"a": 1, "b": 2},
+	"mapC":   map[string]A{"x": {Id: 3, B: B{"baz"}}},
+
 	// TODO: Tests that use TypeBinders
 
 	// Invalid value tests (the result should always be the zero value for that type)
@@ -213,6 +229,19 @@ func TestBinder(t *testing.T) {
 	}
 }
 
+func TestBindDepth(t *testing.T) {
+	defer func(depth int) { MaxBindDepth = depth }(MaxBindDepth)
+	MaxBindDepth = 1
+
+	// a[b][c] is nested too deeply, so a[b] is left empty.
+	params := &Params{Values: map[string][]string{
+		"a[b][c]": {"1"},
+		"z":       {"2"},
+	}}
+	actual := Bind(params, "a", reflect.TypeOf(map[string]map[string]int{}))
+	valEq(t, "a", actual, reflect.ValueOf(map[string]map[string]int{"b": {}}))
+}
+
 // Helpers
 
 func valEq(t *testing.T, name string, actual, expected reflect.Value) {
@@ -232,6 +261,15 @@ func valEq(t *testing.T, name string, actual, expected reflect.Value) {
 	case reflect.Ptr:
 		// Check equality on the element type.
 		valEq(t, name, actual.Elem(), expected.Elem())
+	case reflect.Map:
+		// Check the type/length, and then each expected key.
+		if !eq(t, name+" (type)", actual.Type(), expected.Type()) ||
+			!eq(t, name+" (len)", actual.Len(), expected.Len()) {
+			return
+		}
+		for _, key := range expected.MapKeys() {
+			valEq(t, fmt.Sprintf("%s[%v]", name, key), actual.MapIndex(key), expected.MapIndex(key))
+		}
 	default:
 		eq(t, name, actual.Interface(), expected.Interface())
 	}
@@ -0,0 +1,276 @@
+package revel
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	// The maximum size of request body, in bytes, that the body helpers will read.
+	// Configured with http.maxbodysize in app.conf.
+	MaxBodySize int64 = 32 << 20 // 32 MB
+
+	// If true, the body helpers check that the body is exactly as long as its
+	// declared Content-Length.  Configured with http.verifylength.
+	VerifyContentLength = false
+
+	// If true, Request.ReadJSON rejects objects with fields that the target does
+	// not have, unless the call says otherwise.  Configured with
+	// http.json.disallowunknownfields.
+	DisallowUnknownJSONFields = false
+)
+
+var (
+	ErrBodyTooLarge       = errors.New("request body too large")
+	ErrBodyLengthMismatch = errors.New("request body does not match its Content-Length")
+	ErrMalformedJSON      = errors.New("malformed JSON")
+	ErrUnknownJSONField   = errors.New("unknown JSON field")
+)
+
+// The error returned by Request.ReadJSON for a body that is not valid JSON, or
+// does not fit the target (e.g. a string for an int field).  It matches
+// ErrMalformedJSON.
+type MalformedJSONError struct {
+	Offset int64 // The byte offset in the body at which the error was found.
+	Err    error // The error from encoding/json.
+}
+
+func (e *MalformedJSONError) Error() string {
+	return fmt.Sprintf("malformed JSON at offset %d: %s", e.Offset, e.Err)
+}
+
+func (e *MalformedJSONError) Is(target error) bool {
+	return target == ErrMalformedJSON
+}
+
+func (e *MalformedJSONError) Unwrap() error {
+	return e.Err
+}
+
+// The error returned by Request.ReadJSON for an object field that the target
+// does not have, with DisallowUnknownJSONFields.  It matches ErrUnknownJSONField.
+type UnknownJSONFieldError struct {
+	Field string
+}
+
+func (e *UnknownJSONFieldError) Error() string {
+	return fmt.Sprintf("unknown JSON field %q", e.Field)
+}
+
+func (e *UnknownJSONFieldError) Is(target error) bool {
+	return target == ErrUnknownJSONField
+}
+
+func init() {
+	OnAppStart(func() {
+		MaxBodySize = int64(Config.IntDefault("http.maxbodysize", int(MaxBodySize)))
+		VerifyContentLength = Config.BoolDefault("http.verifylength", false)
+		DisallowUnknownJSONFields = Config.BoolDefault("http.json.disallowunknownfields", false)
+	})
+}
+
+// Return the body size declared by the Content-Length header.
+// Returns false if it is unknown (e.g. for a chunked request).
+func (r *Request) ExpectedBodySize() (int64, bool) {
+	for _, encoding := range r.TransferEncoding {
+		if encoding == "chunked" {
+			return 0, false
+		}
+	}
+	if r.ContentLength < 0 {
+		return 0, false
+	}
+	return r.ContentLength, true
+}
+
+// Read the entire request body, up to MaxBodySize bytes.
+//
+// The body is cached, so it may be requested repeatedly, and Body is reset so
+// that it may be read again from the start by other code.  If the body is
+// larger than MaxBodySize, ErrBodyTooLarge is returned (and Body is left
+// positioned back at the start).  If VerifyContentLength is on, a body that
+// does not match its Content-Length results in ErrBodyLengthMismatch.
+func (r *Request) BodyBytes() ([]byte, error) {
+	if r.body == nil {
+		if r.Body == nil {
+			return []byte{}, nil
+		}
+
+		b, err := ioutil.ReadAll(io.LimitReader(r.Body, MaxBodySize+1))
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(b)) > MaxBodySize {
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(b), r.Body), r.Body}
+			return nil, ErrBodyTooLarge
+		}
+		if expected, ok := r.ExpectedBodySize(); ok && VerifyContentLength && int64(len(b)) != expected {
+			r.Body.Close()
+			return nil, ErrBodyLengthMismatch
+		}
+
+		r.Body.Close()
+		r.body = b
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(r.body))
+	return r.body, nil
+}
+
+// Read the entire request body as a string.
+// See BodyBytes.
+func (r *Request) BodyString() (string, error) {
+	b, err := r.BodyBytes()
+	return string(b), err
+}
+
+// Decode the JSON request body into v, reading at most MaxBodySize bytes.  The
+// body is decoded as it is read, so unlike BodyBytes it is not kept in memory.
+//
+// The errors distinguish the ways in which the body may be unacceptable, so
+// that each may be answered with the right status (see Response.WriteError):
+//   - *UnsupportedMediaTypeError (415) if the Content-Type is not JSON
+//   - ErrBodyTooLarge (413) if the body is larger than MaxBodySize
+//   - *MalformedJSONError (400) if it is not a single valid JSON value for v
+//   - *UnknownJSONFieldError (422) for unknown fields, if they are disallowed
+//
+// Fields that v does not have are disallowed if DisallowUnknownJSONFields is
+// on, or as given for this call, e.g. for one strict endpoint:
+//
+//     err := c.Request.ReadJSON(&order, true)
+func (r *Request) ReadJSON(v interface{}, disallowUnknownFields ...bool) error {
+	if !isJSONMediaType(r.ContentType) {
+		return &UnsupportedMediaTypeError{r.ContentType, []string{"application/json"}}
+	}
+	if size, ok := r.ExpectedBodySize(); ok && size > MaxBodySize {
+		return ErrBodyTooLarge
+	}
+	if r.Body == nil {
+		return &MalformedJSONError{0, io.ErrUnexpectedEOF}
+	}
+
+	body := &limitedReader{r.Body, MaxBodySize, false}
+	decoder := json.NewDecoder(body)
+	strict := DisallowUnknownJSONFields
+	if len(disallowUnknownFields) > 0 {
+		strict = disallowUnknownFields[0]
+	}
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	err := decoder.Decode(v)
+	offset := decoder.InputOffset()
+	if err == nil {
+		if _, err = decoder.Token(); err == io.EOF {
+			err = nil
+		} else {
+			err = errTrailingJSON
+		}
+	}
+	if body.exceeded {
+		return ErrBodyTooLarge
+	}
+
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &syntaxErr):
+		return &MalformedJSONError{syntaxErr.Offset, err}
+	case errors.As(err, &typeErr):
+		return &MalformedJSONError{typeErr.Offset, err}
+	case err == errTrailingJSON:
+		return &MalformedJSONError{offset, err}
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		return &MalformedJSONError{MaxBodySize - body.n, io.ErrUnexpectedEOF}
+	case strings.HasPrefix(err.Error(), unknownJSONFieldPrefix):
+		field, _ := strconv.Unquote(strings.TrimPrefix(err.Error(), unknownJSONFieldPrefix))
+		return &UnknownJSONFieldError{field}
+	}
+	return err
+}
+
+var errTrailingJSON = errors.New("unexpected data after the JSON value")
+
+// The prefix of the error that encoding/json returns for unknown fields, which
+// has no type of its own.
+const unknownJSONFieldPrefix = "json: unknown field "
+
+// Return true for application/json, and other JSON media types, such as those
+// of the json format and "+json" types (e.g. application/merge-patch+json).
+func isJSONMediaType(mediaType string) bool {
+	if strings.HasSuffix(mediaType, "+json") {
+		return true
+	}
+	if format := LookupFormat("json"); format != nil {
+		for _, jsonMediaType := range format.MediaTypes {
+			if strings.EqualFold(mediaType, jsonMediaType) {
+				return true
+			}
+		}
+	}
+	return mediaType == "application/json"
+}
+
+// A reader that reports EOF after n bytes, noting whether there was more.
+type limitedReader struct {
+	io.Reader
+	n        int64
+	exceeded bool
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		var b [1]byte
+		if n, _ := l.Reader.Read(b[:]); n > 0 {
+			l.exceeded = true
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.Reader.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+// Return the trailers received after a chunked request body, or nil if there
+// are none.  The body must have been read to the end first (e.g. with
+// BodyAndTrailers): until then, the trailers have not been received, and only
+// those the client declared in its Trailer header are known, without values.
+func (r *Request) Trailers() http.Header {
+	var trailers http.Header
+	for name, values := range r.Trailer {
+		if len(values) > 0 {
+			if trailers == nil {
+				trailers = make(http.Header)
+			}
+			trailers[name] = append([]string(nil), values...)
+		}
+	}
+	return trailers
+}
+
+// Read the entire request body (see BodyBytes), and then return it along with
+// the trailers received after it (see Trailers).
+func (r *Request) BodyAndTrailers() ([]byte, http.Header, error) {
+	b, err := r.BodyBytes()
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, r.Trailers(), nil
+}
@@ -0,0 +1,159 @@
+package revel
+
+import (
+	"bufio"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBodyBytes(t *testing.T) {
+	httpRequest, _ := http.NewRequest("POST", "/", strings.NewReader("hello world"))
+	req := NewRequest(httpRequest)
+	for i := 0; i < 2; i++ {
+		if body, err := req.BodyString(); err != nil || body != "hello world" {
+			t.Errorf("Expected the body, got %q (%v)", body, err)
+		}
+	}
+	if body, _ := ioutil.ReadAll(req.Body); string(body) != "hello world" {
+		t.Errorf("Expected Body to be restored, got %q", body)
+	}
+
+	defer func(size int64) { MaxBodySize = size }(MaxBodySize)
+	MaxBodySize = 5
+	httpRequest, _ = http.NewRequest("POST", "/", strings.NewReader("hello world"))
+	req = NewRequest(httpRequest)
+	if _, err := req.BodyBytes(); err != ErrBodyTooLarge {
+		t.Errorf("Expected ErrBodyTooLarge, got %v", err)
+	}
+	if body, _ := ioutil.ReadAll(req.Body); string(body) != "hello world" {
+		t.Errorf("Expected Body to be restored, got %q", body)
+	}
+}
+
+func TestVerifyContentLength(t *testing.T) {
+	VerifyContentLength = true
+	defer func() { VerifyContentLength = false }()
+
+	httpRequest, _ := http.NewRequest("POST", "/", strings.NewReader("hello world"))
+	httpRequest.ContentLength = 20
+	req := NewRequest(httpRequest)
+	if size, ok := req.ExpectedBodySize(); !ok || size != 20 {
+		t.Errorf("Expected a declared size of 20, got %d (%v)", size, ok)
+	}
+	if _, err := req.BodyBytes(); err != ErrBodyLengthMismatch {
+		t.Errorf("Expected ErrBodyLengthMismatch, got %v", err)
+	}
+
+	httpRequest, _ = http.NewRequest("POST", "/", strings.NewReader("hello world"))
+	httpRequest.TransferEncoding = []string{"chunked"}
+	req = NewRequest(httpRequest)
+	if _, ok := req.ExpectedBodySize(); ok {
+		t.Error("Expected no declared size for a chunked request")
+	}
+	if _, err := req.BodyBytes(); err != nil {
+		t.Errorf("Expected a chunked body to be read, got %v", err)
+	}
+
+	// A body that is too large is reported as such, whatever its length.
+	defer func(size int64) { MaxBodySize = size }(MaxBodySize)
+	MaxBodySize = 5
+	httpRequest, _ = http.NewRequest("POST", "/", strings.NewReader("hello world"))
+	req = NewRequest(httpRequest)
+	if _, err := req.BodyBytes(); err != ErrBodyTooLarge {
+		t.Errorf("Expected ErrBodyTooLarge, got %v", err)
+	}
+	if body, _ := ioutil.ReadAll(req.Body); string(body) != "hello world" {
+		t.Errorf("Expected Body to be restored, got %q", body)
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	defer func(size int64) { MaxBodySize = size }(MaxBodySize)
+	MaxBodySize = 32
+
+	type target struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	testCases := []struct {
+		contentType, body string
+		strict            bool
+		err               error
+		offset            int64
+	}{
+		{"application/json", `{"name": "a", "count": 2}`, false, nil, 0},
+		{"application/vnd.api+json; charset=utf-8", `{"name": "a"}`, false, nil, 0},
+		{"application/json", `{"name": "a", "extra": 1}`, false, nil, 0},
+		{"application/json", `{"name": "a", "extra": 1}`, true, ErrUnknownJSONField, 0},
+		{"text/plain", `{"name": "a"}`, false, ErrUnsupportedMediaType, 0},
+		{"application/json", `{"name": "a", "count": 2, "more": 3}`, false, ErrBodyTooLarge, 0},
+		{"application/json", `{"name": "a",}`, false, ErrMalformedJSON, 14},
+		{"application/json", `{"count": "two"}`, false, ErrMalformedJSON, 15},
+		{"application/json", `{"name": "a"} {}`, false, ErrMalformedJSON, 13},
+		{"application/json", `{"name": `, false, ErrMalformedJSON, 9},
+		{"application/json", ``, false, ErrMalformedJSON, 0},
+	}
+	for _, testCase := range testCases {
+		DisallowUnknownJSONFields = testCase.strict
+		httpRequest, _ := http.NewRequest("POST", "/", strings.NewReader(testCase.body))
+		httpRequest.Header.Set("Content-Type", testCase.contentType)
+		var v target
+		err := NewRequest(httpRequest).ReadJSON(&v)
+		if testCase.err == nil {
+			if err != nil || v.Name != "a" {
+				t.Errorf("%s: expected it to be decoded, got %+v (%v)", testCase.body, v, err)
+			}
+			continue
+		}
+		if !errors.Is(err, testCase.err) {
+			t.Errorf("%s: expected %v, got %v", testCase.body, testCase.err, err)
+			continue
+		}
+		if status, _ := errorStatusCode(err); status == 0 {
+			t.Errorf("%s: expected %v to have a status", testCase.body, err)
+		}
+		var malformed *MalformedJSONError
+		if errors.As(err, &malformed) && malformed.Offset != testCase.offset {
+			t.Errorf("%s: expected offset %d, got %d", testCase.body, testCase.offset, malformed.Offset)
+		}
+		var unknown *UnknownJSONFieldError
+		if errors.As(err, &unknown) && unknown.Field != "extra" {
+			t.Errorf("%s: expected the unknown field extra, got %q", testCase.body, unknown.Field)
+		}
+	}
+	DisallowUnknownJSONFields = false
+
+	// Each call may override the default.
+	for _, strict := range []bool{false, true} {
+		DisallowUnknownJSONFields = !strict
+		httpRequest, _ := http.NewRequest("POST", "/", strings.NewReader(`{"name": "a", "extra": 1}`))
+		httpRequest.Header.Set("Content-Type", "application/json")
+		if err := NewRequest(httpRequest).ReadJSON(&target{}, strict); errors.Is(err, ErrUnknownJSONField) != strict {
+			t.Errorf("strict=%v: unexpected error %v", strict, err)
+		}
+	}
+	DisallowUnknownJSONFields = false
+}
+
+func TestBodyAndTrailers(t *testing.T) {
+	raw := "POST /upload HTTP/1.1\r\nHost: example.com\r\nTransfer-Encoding: chunked\r\nTrailer: Grpc-Status, Checksum\r\n\r\n" +
+		"5\r\nhello\r\n6\r\n world\r\n0\r\nGrpc-Status: 0\r\nChecksum: abc\r\n\r\n"
+	httpRequest, err := http.ReadRequest(bufio.NewReader(strings.NewReader(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := NewRequest(httpRequest)
+	if trailers := req.Trailers(); trailers != nil {
+		t.Errorf("Expected no trailers before the body is read, got %v", trailers)
+	}
+
+	body, trailers, err := req.BodyAndTrailers()
+	expected := http.Header{"Grpc-Status": {"0"}, "Checksum": {"abc"}}
+	if err != nil || string(body) != "hello world" || !reflect.DeepEqual(trailers, expected) {
+		t.Errorf("Expected the body and %v, got %q and %v (%v)", expected, body, trailers, err)
+	}
+}
@@ -0,0 +1,126 @@
+package revel
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The directives of a request Cache-Control header. (RFC 7234 section 5.2.1)
+type CacheControlDirectives struct {
+	NoCache      bool
+	NoStore      bool
+	NoTransform  bool
+	OnlyIfCached bool
+
+	// The durations are only meaningful if the corresponding Has field is set.
+	// (e.g. max-age=0 is quite different from no max-age)
+	MaxAge, MinFresh, MaxStale          time.Duration
+	HasMaxAge, HasMinFresh, HasMaxStale bool // max-stale without a value accepts any staleness.
+}
+
+// Parse the request Cache-Control header.
+// Unknown directives are ignored, as are malformed values (with a warning).
+func (r *Request) CacheControl() CacheControlDirectives {
+	var directives CacheControlDirectives
+	for _, directive := range splitHeaderList(r.Header["Cache-Control"]) {
+		name, value := directive, ""
+		if i := strings.Index(directive, "="); i != -1 {
+			name, value = directive[:i], strings.Trim(strings.TrimSpace(directive[i+1:]), `"`)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "no-cache":
+			directives.NoCache = true
+		case "no-store":
+			directives.NoStore = true
+		case "no-transform":
+			directives.NoTransform = true
+		case "only-if-cached":
+			directives.OnlyIfCached = true
+		case "max-age":
+			directives.MaxAge, directives.HasMaxAge = parseDeltaSeconds(directive, value)
+		case "min-fresh":
+			directives.MinFresh, directives.HasMinFresh = parseDeltaSeconds(directive, value)
+		case "max-stale":
+			if value == "" {
+				directives.HasMaxStale = true
+			} else {
+				directives.MaxStale, directives.HasMaxStale = parseDeltaSeconds(directive, value)
+			}
+		}
+	}
+	return directives
+}
+
+// Parse the number of seconds given as the value of a directive.
+func parseDeltaSeconds(directive, value string) (time.Duration, bool) {
+	seconds, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		WARN.Printf("Ignoring malformed Cache-Control directive '%s'", directive)
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// The directives of a response Cache-Control header. (RFC 7234 section 5.2.2)
+// Zero-valued fields are omitted, so a max-age of 0 is not sent (use NoCache).
+type ResponseCacheControl struct {
+	Public, Private bool // Mutually exclusive: Private wins if both are set.
+	NoCache         bool
+	NoStore         bool
+	NoTransform     bool
+	MustRevalidate  bool
+	ProxyRevalidate bool
+	Immutable       bool // RFC 8246
+
+	MaxAge  time.Duration
+	SMaxAge time.Duration
+}
+
+// Return the header value, with the directives in a stable order.
+func (cc ResponseCacheControl) String() string {
+	var directives []string
+	switch {
+	case cc.Private:
+		if cc.Public {
+			WARN.Println("Cache-Control may not be both public and private; using private")
+		}
+		directives = append(directives, "private")
+	case cc.Public:
+		directives = append(directives, "public")
+	}
+	flags := []struct {
+		set  bool
+		name string
+	}{
+		{cc.NoCache, "no-cache"},
+		{cc.NoStore, "no-store"},
+		{cc.NoTransform, "no-transform"},
+		{cc.MustRevalidate, "must-revalidate"},
+		{cc.ProxyRevalidate, "proxy-revalidate"},
+		{cc.Immutable, "immutable"},
+	}
+	for _, flag := range flags {
+		if flag.set {
+			directives = append(directives, flag.name)
+		}
+	}
+	if seconds := int64(cc.MaxAge / time.Second); seconds > 0 {
+		directives = append(directives, "max-age="+strconv.FormatInt(seconds, 10))
+	}
+	if seconds := int64(cc.SMaxAge / time.Second); seconds > 0 {
+		directives = append(directives, "s-maxage="+strconv.FormatInt(seconds, 10))
+	}
+	return strings.Join(directives, ", ")
+}
+
+// Set the response Cache-Control header from the given directives.
+// The header is removed if there are no directives.
+func (resp *Response) SetCacheControl(directives ResponseCacheControl) {
+	if value := directives.String(); value != "" {
+		resp.Out.Header().Set("Cache-Control", value)
+	} else {
+		resp.Out.Header().Del("Cache-Control")
+	}
+}
@@ -0,0 +1,46 @@
+package revel
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRequestCacheControl(t *testing.T) {
+	testCases := map[string]CacheControlDirectives{
+		"":                          {},
+		"no-cache":                  {NoCache: true},
+		"No-Store, no-transform":    {NoStore: true, NoTransform: true},
+		"max-age=0, only-if-cached": {OnlyIfCached: true, HasMaxAge: true},
+		`max-age="60", min-fresh=5`: {MaxAge: time.Minute, HasMaxAge: true, MinFresh: 5 * time.Second, HasMinFresh: true},
+		"max-stale":                 {HasMaxStale: true},
+		"max-stale=10":              {HasMaxStale: true, MaxStale: 10 * time.Second},
+		"max-age=soon, no-cache":    {NoCache: true},
+		"max-age=-1":                {},
+	}
+	for header, expected := range testCases {
+		httpRequest, _ := http.NewRequest("GET", "/", nil)
+		httpRequest.Header.Set("Cache-Control", header)
+		if actual := NewRequest(httpRequest).CacheControl(); actual != expected {
+			t.Errorf("%q: expected %+v, got %+v", header, expected, actual)
+		}
+	}
+}
+
+func TestResponseCacheControl(t *testing.T) {
+	testCases := []struct {
+		directives ResponseCacheControl
+		expected   string
+	}{
+		{ResponseCacheControl{}, ""},
+		{ResponseCacheControl{Public: true, MaxAge: time.Hour, Immutable: true}, "public, immutable, max-age=3600"},
+		{ResponseCacheControl{Public: true, Private: true}, "private"},
+		{ResponseCacheControl{NoStore: true, NoCache: true, MustRevalidate: true}, "no-cache, no-store, must-revalidate"},
+		{ResponseCacheControl{MaxAge: 500 * time.Millisecond, SMaxAge: time.Minute}, "s-maxage=60"},
+	}
+	for _, testCase := range testCases {
+		if actual := testCase.directives.String(); actual != testCase.expected {
+			t.Errorf("%+v: expected %q, got %q", testCase.directives, testCase.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package revel
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Declare the canonical URL of the page with a Link header with
+// rel="canonical", replacing any canonical link set before, but keeping the
+// other links.  A relative URL is resolved against the request URL, and made
+// absolute with the request BaseURL (so it is as the client sees it behind a
+// proxy, and under any ForwardedPrefix, as for SafeRedirectURL).  An error is returned for malformed URLs, and absolute ones that are
+// not http or https or that have user info.  Any fragment is dropped.
+func (resp *Response) SetCanonical(req *Request, location string) error {
+	location = strings.TrimSpace(location)
+	u, err := url.Parse(location)
+	if err != nil || location == "" || u.User != nil || u.Opaque != "" || strings.ContainsAny(location, "<>\"") {
+		return fmt.Errorf("invalid canonical URL %q", location)
+	}
+	u.Fragment, u.RawFragment = "", ""
+
+	if u.Scheme == "" && u.Host == "" {
+		location = req.siteURL() + req.externalPath((&url.URL{Path: req.URL.Path}).ResolveReference(u).RequestURI())
+	} else if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid canonical URL %q", location)
+	} else {
+		location = u.String()
+	}
+
+	header := resp.Out.Header()
+	links, replaced := []string(nil), false
+	for _, link := range splitHeaderList(header["Link"]) {
+		if hasLinkRel(link, "canonical") {
+			replaced = true
+		} else {
+			links = append(links, link)
+		}
+	}
+	if replaced {
+		header["Link"] = links
+	}
+	header.Add("Link", "<"+location+`>; rel="canonical"`)
+	return nil
+}
@@ -0,0 +1,55 @@
+package revel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSetCanonical(t *testing.T) {
+	defer func() { TrustedProxies = nil }()
+	TrustedProxies = []string{"10.0.0.0/8"}
+
+	testCases := map[string]string{
+		"/products/1?color=red#top": "https://shop.example.com/store/products/1?color=red",
+		"2":                         "https://shop.example.com/store/products/2",
+		"/store/products/3":         "https://shop.example.com/store/products/3",
+		"https://example.org/a":     "https://example.org/a",
+		"ftp://example.org/a":       "",
+		"https://user@example.org/": "",
+		"":                          "",
+	}
+	for location, expected := range testCases {
+		req := forwardedRequest("10.0.0.1:1234", "internal:9000", map[string]string{
+			"X-Forwarded-Host":   "shop.example.com",
+			"X-Forwarded-Proto":  "https",
+			"X-Forwarded-Prefix": "/store",
+		})
+		req.URL.Path = "/products/1"
+		recorder := httptest.NewRecorder()
+		err := NewResponse(recorder).SetCanonical(req, location)
+		if actual := recorder.Header().Get("Link"); expected == "" {
+			if err == nil || actual != "" {
+				t.Errorf("%q: expected an error, got Link %q", location, actual)
+			}
+		} else if err != nil || actual != "<"+expected+`>; rel="canonical"` {
+			t.Errorf("%q: expected %q, got %q (%v)", location, expected, actual, err)
+		}
+	}
+
+	httpRequest, _ := http.NewRequest("GET", "http://example.com/a", nil)
+	recorder := httptest.NewRecorder()
+	resp := NewResponse(recorder)
+	resp.Out.Header().Add("Link", `</style.css>; rel=preload; as=style, </old>; rel="canonical"`)
+	resp.Out.Header().Add("Link", `</fr>; rel="alternate"; hreflang="fr"`)
+	resp.SetCanonical(NewRequest(httpRequest), "/a")
+	expected := []string{
+		`</style.css>; rel=preload; as=style`,
+		`</fr>; rel="alternate"; hreflang="fr"`,
+		`<http://example.com/a>; rel="canonical"`,
+	}
+	if actual := recorder.Header()["Link"]; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
@@ -0,0 +1,132 @@
+package revel
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// If true, form values posted in a known non-UTF-8 charset are transcoded to
+	// UTF-8 when the params are parsed.  Configured with http.transcode.
+	TranscodeCharsets = false
+
+	// Decoders from the supported charsets to UTF-8, keyed by lower-cased name.
+	// Others may be added, e.g. from golang.org/x/text/encoding:
+	//   revel.CharsetDecoders["shift_jis"] = japanese.ShiftJIS.NewDecoder().Reader
+	CharsetDecoders = map[string]func(io.Reader) io.Reader{
+		"iso-8859-1":   latin1Decoder,
+		"iso8859-1":    latin1Decoder,
+		"latin1":       latin1Decoder,
+		"windows-1252": windows1252Decoder,
+		"cp1252":       windows1252Decoder,
+	}
+
+	ErrUnsupportedCharset = errors.New("unsupported charset")
+)
+
+func init() {
+	OnAppStart(func() {
+		TranscodeCharsets = Config.BoolDefault("http.transcode", false)
+	})
+}
+
+// Return the (lower-cased) charset parameter of the request Content-Type,
+// or "" if none was given.
+func (r *Request) Charset() string {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(params["charset"])
+}
+
+// Return the decoder for the request charset, or nil if the request is already
+// UTF-8 (or compatible).  Returns ErrUnsupportedCharset if there is no decoder.
+func (r *Request) charsetDecoder() (func(io.Reader) io.Reader, error) {
+	switch charset := r.Charset(); charset {
+	case "", "utf-8", "utf8", "us-ascii", "ascii":
+		return nil, nil
+	default:
+		if decoder, ok := CharsetDecoders[charset]; ok {
+			return decoder, nil
+		}
+		return nil, ErrUnsupportedCharset
+	}
+}
+
+// Transcode all keys and values to UTF-8.
+// (Transcoding happens after form decoding, since percent-encoding hides the
+// original bytes from a transcoding body reader.)
+func transcodeValues(decoder func(io.Reader) io.Reader, values url.Values) (url.Values, error) {
+	transcoded := make(url.Values, len(values))
+	for key, vals := range values {
+		utf8Key, err := transcode(decoder, key)
+		if err != nil {
+			return nil, err
+		}
+		for _, val := range vals {
+			utf8Val, err := transcode(decoder, val)
+			if err != nil {
+				return nil, err
+			}
+			transcoded[utf8Key] = append(transcoded[utf8Key], utf8Val)
+		}
+	}
+	return transcoded, nil
+}
+
+func transcode(decoder func(io.Reader) io.Reader, s string) (string, error) {
+	b, err := ioutil.ReadAll(decoder(strings.NewReader(s)))
+	return string(b), err
+}
+
+// A decoder for charsets that map each byte to a single rune.
+type singleByteDecoder struct {
+	reader  io.Reader
+	decode  func(byte) rune
+	pending []byte
+	err     error
+}
+
+func (d *singleByteDecoder) Read(p []byte) (int, error) {
+	if len(d.pending) == 0 && d.err == nil {
+		var buf [512]byte
+		n, err := d.reader.Read(buf[:])
+		for _, b := range buf[:n] {
+			d.pending = utf8.AppendRune(d.pending, d.decode(b))
+		}
+		d.err = err
+	}
+	n := copy(p, d.pending)
+	d.pending = d.pending[n:]
+	if len(d.pending) == 0 && d.err != nil {
+		return n, d.err
+	}
+	return n, nil
+}
+
+func latin1Decoder(r io.Reader) io.Reader {
+	return &singleByteDecoder{reader: r, decode: func(b byte) rune { return rune(b) }}
+}
+
+// Windows-1252 is ISO-8859-1 except for the printable characters at 0x80-0x9F.
+var windows1252Runes = [32]rune{
+	0x20AC, 0x0081, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008D, 0x017D, 0x008F,
+	0x0090, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x009D, 0x017E, 0x0178,
+}
+
+func windows1252Decoder(r io.Reader) io.Reader {
+	return &singleByteDecoder{reader: r, decode: func(b byte) rune {
+		if b >= 0x80 && b <= 0x9F {
+			return windows1252Runes[b-0x80]
+		}
+		return rune(b)
+	}}
+}
@@ -0,0 +1,88 @@
+package revel
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Return true if the client asked for reduced data usage, with Save-Data: on.
+// Responses that depend on it should call resp.AddVary("Save-Data").
+func (r *Request) SaveData() bool {
+	for _, value := range splitHeaderList(r.Header["Save-Data"]) {
+		if strings.EqualFold(value, "on") {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the effective connection type the client reported with the ECT
+// network hint: "slow-2g", "2g", "3g" or "4g", or "" if it is absent or
+// unrecognized.
+func (r *Request) ECT() string {
+	switch ect := strings.ToLower(strings.TrimSpace(r.Header.Get("ECT"))); ect {
+	case "slow-2g", "2g", "3g", "4g":
+		return ect
+	}
+	return ""
+}
+
+// Return the client's estimated bandwidth in megabits per second, from the
+// Downlink network hint, or 0 if it is absent or malformed.
+func (r *Request) Downlink() float64 {
+	downlink, err := strconv.ParseFloat(strings.TrimSpace(r.Header.Get("Downlink")), 64)
+	if err != nil || downlink < 0 {
+		return 0
+	}
+	return downlink
+}
+
+// Return the client's estimated round trip time, from the RTT network hint (in
+// milliseconds), or 0 if it is absent or malformed.
+func (r *Request) RTT() time.Duration {
+	rtt, err := strconv.ParseUint(strings.TrimSpace(r.Header.Get("RTT")), 10, 32)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(rtt) * time.Millisecond
+}
+
+// Return true if the request is a speculative prefetch (or prerender) rather
+// than a navigation by the user, as signalled by the Sec-Purpose, Purpose or
+// X-Moz headers.  Handlers should avoid side effects for such requests.
+func (r *Request) IsPrefetch() bool {
+	for _, name := range []string{"Sec-Purpose", "Purpose", "X-Moz"} {
+		for _, value := range splitHeaderList(r.Header[name]) {
+			// Sec-Purpose is a structured list, e.g. "prefetch;prerender".
+			if purpose := strings.ToLower(strings.TrimSpace(strings.SplitN(value, ";", 2)[0])); purpose == "prefetch" || purpose == "prerender" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Add request headers that the response varies on to its Vary header, skipping
+// those already listed.
+func (resp *Response) AddVary(headers ...string) {
+	header := resp.Out.Header()
+	existing := splitHeaderList(header["Vary"])
+	for _, name := range headers {
+		name = http.CanonicalHeaderKey(name)
+		found := false
+		for _, v := range existing {
+			if v == "*" || strings.EqualFold(v, name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			existing = append(existing, name)
+		}
+	}
+	if len(existing) > 0 {
+		header.Set("Vary", strings.Join(existing, ", "))
+	}
+}
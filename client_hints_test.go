@@ -0,0 +1,62 @@
+package revel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNetworkHints(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/", nil)
+	req := NewRequest(httpRequest)
+	if req.SaveData() || req.ECT() != "" || req.Downlink() != 0 || req.RTT() != 0 {
+		t.Errorf("Expected zero values without hints")
+	}
+
+	httpRequest.Header.Set("Save-Data", "On")
+	httpRequest.Header.Set("ECT", "3g")
+	httpRequest.Header.Set("Downlink", "1.7")
+	httpRequest.Header.Set("RTT", "150")
+	if !req.SaveData() || req.ECT() != "3g" || req.Downlink() != 1.7 || req.RTT() != 150*time.Millisecond {
+		t.Errorf("Unexpected hints: %v %q %v %v", req.SaveData(), req.ECT(), req.Downlink(), req.RTT())
+	}
+
+	httpRequest.Header.Set("ECT", "5g")
+	httpRequest.Header.Set("RTT", "-1")
+	if req.ECT() != "" || req.RTT() != 0 {
+		t.Errorf("Expected malformed hints to be ignored")
+	}
+}
+
+func TestAddVary(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	recorder.Header().Set("Vary", "Accept-Encoding")
+	resp := NewResponse(recorder)
+	resp.AddVary("save-data", "Accept-Encoding")
+	resp.AddVary("Save-Data")
+	if vary := recorder.Header().Get("Vary"); vary != "Accept-Encoding, Save-Data" {
+		t.Errorf("Expected Vary: Accept-Encoding, Save-Data, got %q", vary)
+	}
+}
+
+func TestIsPrefetch(t *testing.T) {
+	testCases := map[string]bool{
+		"":                  false,
+		"Purpose: prefetch": true,
+		"Sec-Purpose: prefetch;anonymous-client-ip": true,
+		"Sec-Purpose: prefetch;prerender":           true,
+		"X-Moz: Prefetch":                           true,
+		"Purpose: navigate":                         false,
+	}
+	for header, expected := range testCases {
+		httpRequest, _ := http.NewRequest("GET", "/", nil)
+		if name, value, ok := strings.Cut(header, ": "); ok {
+			httpRequest.Header.Set(name, value)
+		}
+		if actual := NewRequest(httpRequest).IsPrefetch(); actual != expected {
+			t.Errorf("%q: expected %v, got %v", header, expected, actual)
+		}
+	}
+}
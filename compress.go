@@ -0,0 +1,273 @@
+package revel
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var (
+	// If true, responses are compressed for clients that accept it.
+	// Configured with results.compressed in app.conf.
+	CompressResponses = false
+
+	// Responses smaller than this (in bytes) are not compressed, since it saves
+	// little and may even enlarge them.  Configured with
+	// results.compressed.minsize.
+	CompressionMinSize = 1024
+)
+
+func init() {
+	OnAppStart(func() {
+		CompressResponses = Config.BoolDefault("results.compressed", false)
+		CompressionMinSize = Config.IntDefault("results.compressed.minsize", CompressionMinSize)
+	})
+}
+
+// A content coding that responses may be compressed with.
+type encoder struct {
+	name       string
+	newEncoder func(w io.Writer) io.WriteCloser
+}
+
+// The supported content codings, in order of server preference, which breaks
+// ties between codings the client accepts equally.  Brotli is added to these by
+// building with the brotli tag (see compress_brotli.go).
+var encoders = []encoder{
+	{"gzip", func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }},
+}
+
+// Return the supported content coding with the highest quality in the
+// Accept-Encoding header, or "" if none is acceptable (and the response should
+// not be encoded).  e.g. "gzip;q=0.5, br" selects br if it is supported, and
+// gzip otherwise.
+func (r *Request) negotiateEncoding() (name string, newEncoder func(io.Writer) io.WriteCloser) {
+	var best float32
+	for _, encoder := range encoders {
+		if quality := acceptEncodingQuality(r.Header[headerAcceptEncoding], encoder.name); quality > best {
+			best, name, newEncoder = quality, encoder.name, encoder.newEncoder
+		}
+	}
+	return name, newEncoder
+}
+
+// Return the quality the Accept-Encoding header gives the content coding.
+// A coding that is not listed (nor matched by "*") is not acceptable.
+func acceptEncodingQuality(header []string, coding string) float32 {
+	var wildcard float32
+	for _, element := range splitHeaderList(header) {
+		name, q := element, float32(1)
+		if i := strings.Index(element, ";"); i != -1 {
+			name = strings.TrimSpace(element[:i])
+			for _, param := range strings.Split(element[i+1:], ";") {
+				if key, value, ok := strings.Cut(strings.TrimSpace(param), "="); ok && strings.EqualFold(key, "q") {
+					if q, ok = parseQuality(value); !ok {
+						q = 0
+					}
+				}
+			}
+		}
+		switch {
+		case strings.EqualFold(name, coding):
+			return q
+		case name == "*":
+			wildcard = q
+		}
+	}
+	return wildcard
+}
+
+// Compress the response, if the client accepts a supported content coding.
+// It must be called before the response is written, and the response must be
+// finished with Response.Close.  (The server does both when CompressResponses
+// is on.)
+//
+// The body is buffered until it reaches CompressionMinSize, so that smaller
+// responses are sent uncompressed.
+//
+// Compression is skipped if the request or the response has Cache-Control:
+// no-transform, or if the client only accepts the identity coding.
+//
+// Range requests take precedence over compression: a partial (206) response is
+// never compressed, since the byte ranges are of the uncompressed body.  When a
+// full response is compressed, Accept-Ranges is set to "none", since the client
+// can not range into a stream whose encoding it did not ask for.  Compressed
+// responses also carry a 214 (Transformation Applied) Warning.
+func (resp *Response) Compress(req *Request) {
+	resp.AddVary("Accept-Encoding")
+	if req.Method == "HEAD" {
+		return
+	}
+	if req.CacheControl().NoTransform {
+		TRACE.Println("Not compressing the response: the request has Cache-Control: no-transform")
+		return
+	}
+	name, newEncoder := req.negotiateEncoding()
+	if name == "" {
+		TRACE.Printf("Not compressing the response: no supported coding is acceptable (Accept-Encoding: %q)",
+			headerValue(req.Header, headerAcceptEncoding))
+		return
+	}
+	resp.compressing = true
+	resp.WrapWriter(func(w http.ResponseWriter) http.ResponseWriter {
+		return &compressWriter{
+			ResponseWriter: w,
+			resp:           resp,
+			encoding:       name,
+			newEncoder:     newEncoder,
+			minSize:        CompressionMinSize,
+		}
+	})
+}
+
+// A ResponseWriter that compresses the body once it is known to be at least
+// minSize bytes.  The status and headers are held back until then, so that the
+// Content-Encoding may be set.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding   string
+	newEncoder func(io.Writer) io.WriteCloser
+	minSize    int
+	resp       *Response // Counts the bytes written, before compression.
+
+	status  int
+	buf     []byte
+	decided bool
+	encoder io.WriteCloser // nil if the body is not being compressed.
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if status >= 100 && status < 200 {
+		// Informational responses are sent on ahead.
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if !w.decided && w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	w.resp.bytesWritten += int64(len(b))
+	if !w.decided {
+		w.buf = append(w.buf, b...)
+		if len(w.buf) < w.minSize {
+			return len(b), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if w.encoder != nil {
+		return w.encoder.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Decide whether to compress the body, write the header, and write out the
+// buffered body.  Compression is skipped for bodiless statuses, partial
+// content, already-encoded bodies, bodies with a digest (see
+// Response.SetDigest), no-transform responses, and content types that are not
+// compressible.
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+	header := w.Header()
+	if compress && hasNoTransform(header) {
+		TRACE.Println("Not compressing the response: it has Cache-Control: no-transform")
+		compress = false
+	}
+	if compress && w.status != http.StatusNoContent && w.status != http.StatusNotModified &&
+		w.status != http.StatusPartialContent && header.Get("Content-Range") == "" &&
+		header.Get("Content-Encoding") == "" && header.Get("Content-Digest") == "" &&
+		isCompressible(header.Get("Content-Type")) {
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		if header.Get("Accept-Ranges") != "" {
+			header.Set("Accept-Ranges", "none")
+		}
+		addWarning(header, 214, warningAgent, "Transformation Applied")
+		w.encoder = w.newEncoder(w.ResponseWriter)
+	}
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.encoder != nil {
+		_, err = w.encoder.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// Flush the body written so far.  Since the final size is unknown, flushing a
+// body that has not reached the minimum size commits to compressing it.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(len(w.buf) > 0)
+	}
+	if flusher, ok := w.encoder.(interface {
+		Flush() error
+	}); ok {
+		flusher.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Write out a body that never reached the minimum size uncompressed, and
+// finish the compressed stream.
+func (w *compressWriter) Close() error {
+	if !w.decided {
+		if err := w.decide(false); err != nil {
+			return err
+		}
+	}
+	if w.encoder != nil {
+		return w.encoder.Close()
+	}
+	return nil
+}
+
+// Return true if the response Cache-Control header has the no-transform
+// directive.
+func hasNoTransform(header http.Header) bool {
+	for _, directive := range splitHeaderList(header["Cache-Control"]) {
+		if strings.EqualFold(directive, "no-transform") {
+			return true
+		}
+	}
+	return false
+}
+
+// Return true if the content type is worth compressing: text, and the common
+// structured text types.  Event streams are not, since each event is flushed as
+// it is sent (see Response.SSE).
+func isCompressible(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	if i := strings.Index(contentType, ";"); i != -1 {
+		contentType = strings.TrimSpace(contentType[:i])
+	}
+	switch {
+	case contentType == "text/event-stream":
+		return false
+	case strings.HasPrefix(contentType, "text/"),
+		strings.HasSuffix(contentType, "+json"),
+		strings.HasSuffix(contentType, "+xml"):
+		return true
+	}
+	switch contentType {
+	case "application/json", "application/xml", "application/javascript":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,19 @@
+//go:build brotli
+// +build brotli
+
+package revel
+
+import (
+	"io"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Brotli (br) compression, which is only compiled in with the brotli build tag
+// (go build -tags brotli), since it depends on github.com/andybalholm/brotli.
+// It is preferred to gzip when the client accepts both equally.
+func init() {
+	encoders = append([]encoder{
+		{"br", func(w io.Writer) io.WriteCloser { return brotli.NewWriterLevel(w, brotli.DefaultCompression) }},
+	}, encoders...)
+}
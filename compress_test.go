@@ -0,0 +1,183 @@
+package revel
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAcceptEncodingQuality(t *testing.T) {
+	testCases := map[string]float32{
+		"":                    0,
+		"gzip":                1,
+		"GZIP;q=0.5":          0.5,
+		"deflate, gzip;q=0":   0,
+		"*;q=0.3":             0.3,
+		"gzip;q=0.8, *;q=0.1": 0.8,
+		"identity":            0,
+	}
+	for header, expected := range testCases {
+		if actual := acceptEncodingQuality([]string{header}, "gzip"); actual != expected {
+			t.Errorf("%q: expected %v, got %v", header, expected, actual)
+		}
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	gzipOnly := []encoder{encoders[len(encoders)-1]}
+	withBrotli := append([]encoder{{"br", nil}}, gzipOnly...)
+	defer func(e []encoder) { encoders = e }(encoders)
+
+	testCases := []struct {
+		acceptEncoding       string
+		withBrotli, gzipOnly string
+	}{
+		{"gzip, deflate, br", "br", "gzip"},
+		{"br;q=1.0, gzip;q=0.8", "br", "gzip"},
+		{"br;q=0.5, gzip", "gzip", "gzip"},
+		{"br", "br", ""},
+		{"br, gzip;q=0", "br", ""},
+		{"*", "br", "gzip"},
+		{"identity", "", ""},
+	}
+	for _, testCase := range testCases {
+		httpRequest, _ := http.NewRequest("GET", "/", nil)
+		httpRequest.Header.Set("Accept-Encoding", testCase.acceptEncoding)
+		req := NewRequest(httpRequest)
+		encoders = withBrotli
+		if name, _ := req.negotiateEncoding(); name != testCase.withBrotli {
+			t.Errorf("%q with brotli: expected %q, got %q", testCase.acceptEncoding, testCase.withBrotli, name)
+		}
+		encoders = gzipOnly
+		if name, _ := req.negotiateEncoding(); name != testCase.gzipOnly {
+			t.Errorf("%q without brotli: expected %q, got %q", testCase.acceptEncoding, testCase.gzipOnly, name)
+		}
+	}
+}
+
+func TestCompressMinSize(t *testing.T) {
+	defer func(size int) { CompressionMinSize = size }(CompressionMinSize)
+	CompressionMinSize = 10
+
+	testCases := map[string]bool{
+		"short":                        false,
+		"long enough to be compressed": true,
+	}
+	for body, compressed := range testCases {
+		httpRequest, _ := http.NewRequest("GET", "/", nil)
+		httpRequest.Header.Set("Accept-Encoding", "gzip")
+		recorder := httptest.NewRecorder()
+		resp := NewResponse(recorder)
+		resp.Compress(NewRequest(httpRequest))
+		resp.Status = http.StatusCreated
+		resp.WriteHeader(http.StatusOK, "text/plain")
+		for _, word := range strings.SplitAfter(body, " ") {
+			resp.Out.Write([]byte(word))
+		}
+		resp.Close()
+
+		if recorder.Code != http.StatusCreated {
+			t.Errorf("%q: expected status 201, got %d", body, recorder.Code)
+		}
+		if encoding := recorder.Header().Get("Content-Encoding"); (encoding == "gzip") != compressed {
+			t.Errorf("%q: expected compressed=%v, got Content-Encoding %q", body, compressed, encoding)
+		}
+		if warning := recorder.Header().Get("Warning"); (warning == `214 revel "Transformation Applied"`) != compressed {
+			t.Errorf("%q: expected compressed=%v, got Warning %q", body, compressed, warning)
+		}
+		actual := recorder.Body.String()
+		if compressed {
+			reader, err := gzip.NewReader(recorder.Body)
+			if err != nil {
+				t.Errorf("%q: %s", body, err)
+				continue
+			}
+			b, _ := ioutil.ReadAll(reader)
+			actual = string(b)
+		}
+		if actual != body {
+			t.Errorf("Expected body %q, got %q", body, actual)
+		}
+	}
+}
+
+func TestCompressRanges(t *testing.T) {
+	defer func(size int) { CompressionMinSize = size }(CompressionMinSize)
+	CompressionMinSize = 10
+	body := strings.Repeat("hello world ", 10)
+
+	testCases := []struct {
+		rangeHeader  string
+		status       int
+		encoding     string
+		acceptRanges string
+	}{
+		{"", http.StatusOK, "gzip", "none"},
+		{"bytes=0-4", http.StatusPartialContent, "", "bytes"},
+	}
+	for _, testCase := range testCases {
+		httpRequest, _ := http.NewRequest("GET", "/", nil)
+		httpRequest.Header.Set("Accept-Encoding", "gzip")
+		if testCase.rangeHeader != "" {
+			httpRequest.Header.Set("Range", testCase.rangeHeader)
+		}
+		req, recorder := NewRequest(httpRequest), httptest.NewRecorder()
+		resp := NewResponse(recorder)
+		resp.Compress(req)
+		(&BinaryResult{Reader: strings.NewReader(body), Name: "hello", Delivery: Inline}).Apply(req, resp)
+		resp.Close()
+
+		header := recorder.Header()
+		if recorder.Code != testCase.status {
+			t.Errorf("%q: expected status %d, got %d", testCase.rangeHeader, testCase.status, recorder.Code)
+		}
+		if actual := header.Get("Content-Encoding"); actual != testCase.encoding {
+			t.Errorf("%q: expected Content-Encoding %q, got %q", testCase.rangeHeader, testCase.encoding, actual)
+		}
+		if actual := header.Get("Accept-Ranges"); actual != testCase.acceptRanges {
+			t.Errorf("%q: expected Accept-Ranges %q, got %q", testCase.rangeHeader, testCase.acceptRanges, actual)
+		}
+		if testCase.status == http.StatusPartialContent && recorder.Body.String() != "hello" {
+			t.Errorf("%q: expected the uncompressed range, got %q", testCase.rangeHeader, recorder.Body)
+		}
+	}
+}
+
+func TestCompressSkipped(t *testing.T) {
+	defer func(size int) { CompressionMinSize = size }(CompressionMinSize)
+	CompressionMinSize = 1
+
+	testCases := []struct {
+		acceptEncoding, requestCacheControl, responseCacheControl string
+		compressed                                                bool
+	}{
+		{"gzip", "", "max-age=60", true},
+		{"identity", "", "", false},
+		{"gzip;q=0, identity", "", "", false},
+		{"gzip", "no-transform", "", false},
+		{"gzip", "", "public, No-Transform", false},
+	}
+	for _, testCase := range testCases {
+		httpRequest, _ := http.NewRequest("GET", "/", nil)
+		httpRequest.Header.Set("Accept-Encoding", testCase.acceptEncoding)
+		if testCase.requestCacheControl != "" {
+			httpRequest.Header.Set("Cache-Control", testCase.requestCacheControl)
+		}
+		recorder := httptest.NewRecorder()
+		resp := NewResponse(recorder)
+		resp.Compress(NewRequest(httpRequest))
+		if testCase.responseCacheControl != "" {
+			resp.Out.Header().Set("Cache-Control", testCase.responseCacheControl)
+		}
+		resp.WriteHeader(http.StatusOK, "text/plain")
+		resp.Out.Write([]byte("hello world"))
+		resp.Close()
+
+		if encoding := recorder.Header().Get("Content-Encoding"); (encoding == "gzip") != testCase.compressed {
+			t.Errorf("%+v: expected compressed=%v, got Content-Encoding %q", testCase, testCase.compressed, encoding)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package revel
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Return the entity tags listed in the If-None-Match header.
+// Each tag is returned as sent, including its quotes and any W/ prefix
+// (e.g. `"xyzzy"`, `W/"r2d2"`), or as "*" for the match-any token.
+// Returns nil if the header is absent.
+func (r *Request) IfNoneMatch() []string {
+	return parseETagList(r.Header["If-None-Match"])
+}
+
+// Return the entity tags listed in the If-Match header.
+// See IfNoneMatch for the format of the returned tags.
+func (r *Request) IfMatch() []string {
+	return parseETagList(r.Header["If-Match"])
+}
+
+// Split the values of an entity tag list header into individual tags.
+func parseETagList(values []string) []string {
+	return splitHeaderList(values)
+}
+
+// Compare two entity tags.
+// With weak comparison, tags match if their opaque values are equal regardless
+// of either being weak.  Strong comparison requires both tags to be strong.
+func etagsMatch(a, b string, weak bool) bool {
+	aWeak, bWeak := strings.HasPrefix(a, "W/"), strings.HasPrefix(b, "W/")
+	if !weak && (aWeak || bWeak) {
+		return false
+	}
+	return strings.TrimPrefix(a, "W/") == strings.TrimPrefix(b, "W/")
+}
+
+// Return true if the request has any precondition header: If-Match,
+// If-None-Match, If-Modified-Since, If-Unmodified-Since or If-Range.
+func (r *Request) HasConditional() bool {
+	for _, name := range []string{"If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since", "If-Range"} {
+		if _, ok := r.Header[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Write a 428 Precondition Required error, in the request format, for unsafe
+// requests that must be made conditional (with If-Match) to avoid lost updates.
+func (resp *Response) PreconditionRequired(req *Request) {
+	resp.writeStatusError(req, http.StatusPreconditionRequired, "Precondition Required",
+		"This request must be conditional: send If-Match with the entity tag of the current representation.")
+}
+
+// Return true if the client's cached copy of the representation, with the
+// given entity tag and modification time, is current, so that a GET or HEAD
+// should be answered with 304 Not Modified.
+//
+// If-None-Match is used if present (with weak comparison), in which case
+// If-Modified-Since is ignored, as required by RFC 7232 section 6.  Otherwise
+// the modification time (to the second) must not be later than
+// If-Modified-Since.  An empty etag or zero modtime never matches.  Always
+// false for other methods.
+func (r *Request) IsNotModified(etag string, modtime time.Time) bool {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		return false
+	}
+	if tags := r.IfNoneMatch(); tags != nil {
+		for _, tag := range tags {
+			if tag == "*" || (etag != "" && etagsMatch(tag, etag, true)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if modtime.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !modtime.Truncate(time.Second).After(since)
+}
+
+// The headers of the full response that a 304 Not Modified must also carry, so
+// that caches can update the metadata of their stored copy. (RFC 9110 section
+// 15.4.5)
+var notModifiedHeaders = []string{"Cache-Control", "Content-Location", "Date", "ETag", "Expires", "Last-Modified"}
+
+// The representation headers that do not belong on a 304 Not Modified, as it
+// has no body for them to describe.
+var notModifiedOmittedHeaders = []string{"Content-Encoding", "Content-Language", "Content-Length",
+	"Content-Range", "Content-Type", "Transfer-Encoding"}
+
+// Write a 304 Not Modified response, e.g. after IsNotModified.
+//
+// The Cache-Control, Content-Location, Date, ETag and Expires headers that the
+// full response would have had are taken from headersToEcho, in preference to
+// any already set on the response, and Last-Modified too when there is no ETag.
+// Its Vary header is added to the response's own (e.g. from compression), so
+// that caches key the response as before.  The other representation headers
+// (e.g. Content-Type and Content-Length) are removed.  Returns
+// ErrHeaderWritten if the response has already started.
+//
+//     header := http.Header{}
+//     header.Set("ETag", etag)
+//     header.Set("Cache-Control", "max-age=60")
+//     if c.Request.IsNotModified(etag, time.Time{}) {
+//         c.Response.NotModified(header)
+//     }
+func (resp *Response) NotModified(headersToEcho http.Header) error {
+	if resp.wroteHeader {
+		return ErrHeaderWritten
+	}
+	header := resp.Out.Header()
+	for _, name := range notModifiedHeaders {
+		if values := headersToEcho.Values(name); len(values) > 0 {
+			header[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+		}
+	}
+	resp.AddVary(splitHeaderList(headersToEcho["Vary"])...)
+	if header.Get("ETag") != "" {
+		header.Del("Last-Modified")
+	}
+	for _, name := range notModifiedOmittedHeaders {
+		header.Del(name)
+	}
+	resp.Status = http.StatusNotModified
+	resp.Out.WriteHeader(http.StatusNotModified)
+	resp.wroteHeader = true
+	return nil
+}
@@ -0,0 +1,169 @@
+package revel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseETagList(t *testing.T) {
+	testCases := []struct {
+		values   []string
+		expected []string
+	}{
+		{nil, nil},
+		{[]string{"*"}, []string{"*"}},
+		{[]string{`"xyzzy"`}, []string{`"xyzzy"`}},
+		{[]string{` "xyzzy", W/"r2d2xxxx" ,"c3piozzzz"`}, []string{`"xyzzy"`, `W/"r2d2xxxx"`, `"c3piozzzz"`}},
+		{[]string{`"a,b", "c"`, `W/"d"`}, []string{`"a,b"`, `"c"`, `W/"d"`}},
+		{[]string{`,, "a" ,`}, []string{`"a"`}},
+	}
+	for _, testCase := range testCases {
+		if actual := parseETagList(testCase.values); !reflect.DeepEqual(actual, testCase.expected) {
+			t.Errorf("%q: expected %q, got %q", testCase.values, testCase.expected, actual)
+		}
+	}
+
+	httpRequest, _ := http.NewRequest("GET", "/", nil)
+	httpRequest.Header.Set("If-Match", `"a", "b"`)
+	req := NewRequest(httpRequest)
+	if actual := req.IfMatch(); !reflect.DeepEqual(actual, []string{`"a"`, `"b"`}) {
+		t.Errorf("Unexpected If-Match tags: %q", actual)
+	}
+	if actual := req.IfNoneMatch(); actual != nil {
+		t.Errorf("Expected no If-None-Match tags, got %q", actual)
+	}
+}
+
+func TestETagsMatch(t *testing.T) {
+	testCases := []struct {
+		a, b         string
+		weak, strong bool
+	}{
+		{`W/"1"`, `W/"1"`, true, false},
+		{`W/"1"`, `W/"2"`, false, false},
+		{`W/"1"`, `"1"`, true, false},
+		{`"1"`, `"1"`, true, true},
+	}
+	for _, testCase := range testCases {
+		if actual := etagsMatch(testCase.a, testCase.b, true); actual != testCase.weak {
+			t.Errorf("%s %s: expected weak match %v", testCase.a, testCase.b, testCase.weak)
+		}
+		if actual := etagsMatch(testCase.a, testCase.b, false); actual != testCase.strong {
+			t.Errorf("%s %s: expected strong match %v", testCase.a, testCase.b, testCase.strong)
+		}
+	}
+}
+
+func TestPreconditionRequired(t *testing.T) {
+	httpRequest, _ := http.NewRequest("PUT", "/", nil)
+	req := NewRequest(httpRequest)
+	if req.HasConditional() {
+		t.Errorf("Expected no conditional headers")
+	}
+	httpRequest.Header.Set("If-Unmodified-Since", "Sat, 29 Oct 1994 19:43:31 GMT")
+	if !req.HasConditional() {
+		t.Errorf("Expected If-Unmodified-Since to be a conditional header")
+	}
+
+	recorder := httptest.NewRecorder()
+	NewResponse(recorder).PreconditionRequired(newTestRequest("json"))
+	if recorder.Code != http.StatusPreconditionRequired {
+		t.Errorf("Expected a 428, got %d", recorder.Code)
+	}
+}
+
+func TestIsNotModified(t *testing.T) {
+	modtime := time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC)
+	before := modtime.Add(-time.Hour).Format(http.TimeFormat)
+	at := modtime.Format(http.TimeFormat)
+
+	testCases := []struct {
+		method, ifNoneMatch, ifModifiedSince string
+		expected                             bool
+	}{
+		{"GET", "", "", false},
+		{"GET", `"v1"`, "", true},
+		{"GET", `"v0", W/"v1"`, "", true},
+		{"GET", "*", "", true},
+		{"GET", `"v2"`, "", false},
+		{"GET", "", at, true},
+		{"GET", "", before, false},
+		{"GET", "", "yesterday", false},
+		// If-None-Match takes precedence over If-Modified-Since.
+		{"GET", `"v2"`, at, false},
+		{"GET", `"v1"`, before, true},
+		{"HEAD", `"v1"`, "", true},
+		{"POST", `"v1"`, at, false},
+	}
+	for _, testCase := range testCases {
+		httpRequest, _ := http.NewRequest(testCase.method, "/", nil)
+		if testCase.ifNoneMatch != "" {
+			httpRequest.Header.Set("If-None-Match", testCase.ifNoneMatch)
+		}
+		if testCase.ifModifiedSince != "" {
+			httpRequest.Header.Set("If-Modified-Since", testCase.ifModifiedSince)
+		}
+		if actual := NewRequest(httpRequest).IsNotModified(`"v1"`, modtime); actual != testCase.expected {
+			t.Errorf("%+v: expected %v, got %v", testCase, testCase.expected, actual)
+		}
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	resp := NewResponse(recorder)
+	header := recorder.Header()
+	header.Set("Content-Type", "text/html")
+	header.Set("Content-Length", "42")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Vary", "Accept-Encoding")
+	header.Set("Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT")
+	header.Set("X-Request-Id", "abc")
+
+	echo := http.Header{}
+	echo.Set("ETag", `"v2"`)
+	echo.Set("Cache-Control", "max-age=60")
+	echo.Add("Vary", "Accept")
+	echo.Add("Vary", "Accept-Language")
+	echo.Set("Expires", "Thu, 22 Oct 2015 07:28:00 GMT")
+	echo.Set("Content-Location", "/doc.json")
+	echo.Set("Content-Type", "application/json")
+	if err := resp.NotModified(echo); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if recorder.Code != http.StatusNotModified || resp.Status != http.StatusNotModified {
+		t.Errorf("Expected a 304, got %d (%d)", recorder.Code, resp.Status)
+	}
+	expected := http.Header{
+		"Etag":             {`"v2"`},
+		"Cache-Control":    {"max-age=60"},
+		"Vary":             {"Accept-Encoding, Accept, Accept-Language"},
+		"Expires":          {"Thu, 22 Oct 2015 07:28:00 GMT"},
+		"Content-Location": {"/doc.json"},
+		"X-Request-Id":     {"abc"},
+	}
+	if !reflect.DeepEqual(recorder.Header(), expected) {
+		t.Errorf("Expected headers %v, got %v", expected, recorder.Header())
+	}
+
+	// Without an ETag, Last-Modified is kept, and the response's own headers
+	// are left.
+	recorder = httptest.NewRecorder()
+	recorder.Header().Set("Cache-Control", "no-cache")
+	recorder.Header().Set("Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT")
+	NewResponse(recorder).NotModified(nil)
+	expected = http.Header{
+		"Cache-Control": {"no-cache"},
+		"Last-Modified": {"Wed, 21 Oct 2015 07:28:00 GMT"},
+	}
+	if !reflect.DeepEqual(recorder.Header(), expected) {
+		t.Errorf("Expected headers %v, got %v", expected, recorder.Header())
+	}
+
+	if err := resp.NotModified(echo); err != ErrHeaderWritten {
+		t.Errorf("Expected ErrHeaderWritten once the header is written, got %v", err)
+	}
+}
@@ -188,7 +188,7 @@ func (c *Controller) Render(extraRenderArgs ...interface{}) Result {
 			"(Method", methodType, ", ViewName", viewName, ")")
 	}
 
-	return c.RenderTemplate(c.Name + "/" + viewName + "." + c.Request.Format)
+	return c.RenderTemplate(c.Name + "/" + viewName + "." + c.Request.TemplateSuffix())
 }
 
 // A less magical way to render a template.
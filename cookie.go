@@ -0,0 +1,51 @@
+package revel
+
+import (
+	"net/http"
+)
+
+// If true, cookies set with Response.SetCookie are marked Secure, so that they
+// are only sent over HTTPS.  Configured with cookie.secure in app.conf.
+var CookieSecure = false
+
+func init() {
+	OnAppStart(func() {
+		CookieSecure = Config.BoolDefault("cookie.secure", false)
+	})
+}
+
+// Set a cookie on the response, filling in secure defaults for the attributes
+// that are unset: the Path defaults to "/", SameSite to Lax, and Secure to
+// CookieSecure.  (HttpOnly is left to the caller, since it can not be told
+// apart from an explicit false.)
+func (resp *Response) SetCookie(cookie *http.Cookie) {
+	if cookie.Path == "" {
+		cookie.Path = "/"
+	}
+	if cookie.SameSite == http.SameSiteDefaultMode {
+		cookie.SameSite = http.SameSiteLaxMode
+	}
+	cookie.Secure = cookie.Secure || CookieSecure
+	http.SetCookie(resp.Out, cookie)
+}
+
+// Return the request's cookies as a map of name to value.  If a name is sent
+// more than once, the first value wins.  (The embedded Request.Cookie and
+// Request.Request.Cookies give the full http.Cookie.)
+func (r *Request) Cookies() map[string]string {
+	cookies := make(map[string]string)
+	for _, cookie := range r.Request.Cookies() {
+		if _, ok := cookies[cookie.Name]; !ok {
+			cookies[cookie.Name] = cookie.Value
+		}
+	}
+	return cookies
+}
+
+// Return the value of the named cookie, or def if the request has none.
+func (r *Request) CookieValue(name, def string) string {
+	if cookie, err := r.Cookie(name); err == nil {
+		return cookie.Value
+	}
+	return def
+}
@@ -0,0 +1,24 @@
+package revel
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCookies(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/", nil)
+	httpRequest.Header.Add("Cookie", "a=1; b=2")
+	httpRequest.Header.Add("Cookie", "a=3")
+	req := NewRequest(httpRequest)
+
+	cookies := req.Cookies()
+	if len(cookies) != 2 || cookies["a"] != "1" || cookies["b"] != "2" {
+		t.Errorf("Unexpected cookies %v", cookies)
+	}
+	if actual := req.CookieValue("b", "none"); actual != "2" {
+		t.Errorf("Expected the cookie value 2, got %q", actual)
+	}
+	if actual := req.CookieValue("c", "none"); actual != "none" {
+		t.Errorf("Expected the default for a missing cookie, got %q", actual)
+	}
+}
@@ -0,0 +1,128 @@
+package revel
+
+import (
+	"net/http"
+	"strings"
+)
+
+// The headers that a page may set on a cross-origin request without causing a
+// CORS preflight, as long as their values are safe.  (Fetch standard, "CORS-
+// safelisted request-header")
+var corsSafelistedHeaders = map[string]bool{
+	"Accept":           true,
+	"Accept-Language":  true,
+	"Content-Language": true,
+	"Content-Type":     true,
+	"Range":            true,
+}
+
+// The headers that are not set by the page, but by the browser itself
+// (forbidden request headers) or by intermediaries, and so do not affect
+// whether a request needs a preflight.
+var corsIgnoredHeaders = map[string]bool{
+	"Accept-Charset":                 true,
+	"Accept-Encoding":                true,
+	"Access-Control-Request-Headers": true,
+	"Access-Control-Request-Method":  true,
+	"Cache-Control":                  true,
+	"Connection":                     true,
+	"Content-Length":                 true,
+	"Cookie":                         true,
+	"Cookie2":                        true,
+	"Date":                           true,
+	"Dnt":                            true,
+	"Expect":                         true,
+	"Forwarded":                      true,
+	"Host":                           true,
+	"Keep-Alive":                     true,
+	"Origin":                         true,
+	"Pragma":                         true,
+	"Priority":                       true,
+	"Referer":                        true,
+	"Te":                             true,
+	"Trailer":                        true,
+	"Transfer-Encoding":              true,
+	"Upgrade":                        true,
+	"Upgrade-Insecure-Requests":      true,
+	"User-Agent":                     true,
+	"Via":                            true,
+	"X-Real-Ip":                      true,
+}
+
+// The Content-Type values allowed on requests without a preflight.
+var corsSafelistedContentTypes = []string{
+	"application/x-www-form-urlencoded",
+	"multipart/form-data",
+	"text/plain",
+}
+
+// Return true if the request is a CORS "simple" request: one that a browser
+// sends cross-origin without a preflight.  Its method is GET, HEAD or POST,
+// and it has no headers beyond those the browser sets itself and the
+// safelisted ones: Accept, Accept-Language, Content-Language, Content-Type (of
+// application/x-www-form-urlencoded, multipart/form-data or text/plain) and a
+// single byte Range, with values that are short and have no unsafe bytes.
+func (r *Request) IsCORSSimple() bool {
+	if r.Method != "GET" && r.Method != "HEAD" && r.Method != "POST" {
+		return false
+	}
+	total := 0
+	for name, values := range r.Header {
+		name = http.CanonicalHeaderKey(name)
+		if corsIgnoredHeaders[name] || strings.HasPrefix(name, "Sec-") || strings.HasPrefix(name, "Proxy-") ||
+			strings.HasPrefix(name, "X-Forwarded-") {
+			continue
+		}
+		if !corsSafelistedHeaders[name] || len(values) != 1 || !isCORSSafelistedValue(name, values[0]) {
+			return false
+		}
+		total += len(values[0])
+	}
+	return total <= 1024
+}
+
+// Return true if the value of a safelisted header does not cause a preflight.
+func isCORSSafelistedValue(name, value string) bool {
+	if len(value) > 128 {
+		return false
+	}
+	switch name {
+	case "Accept":
+		return !strings.ContainsAny(value, corsUnsafeBytes)
+	case "Accept-Language", "Content-Language":
+		return strings.Trim(value, "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz *,-.;=") == ""
+	case "Content-Type":
+		if strings.ContainsAny(value, corsUnsafeBytes) {
+			return false
+		}
+		mediaType := strings.ToLower(strings.TrimSpace(strings.Split(value, ";")[0]))
+		return ContainsString(corsSafelistedContentTypes, mediaType)
+	case "Range":
+		return isSimpleRange(value)
+	}
+	return false
+}
+
+// The bytes that may not appear in the value of a safelisted header: the
+// controls (other than tab), and `"():<>?@[\]{}` and DEL.
+const corsUnsafeBytes = "\x00\x01\x02\x03\x04\x05\x06\x07\x08\x0a\x0b\x0c\x0d\x0e\x0f" +
+	"\x10\x11\x12\x13\x14\x15\x16\x17\x18\x19\x1a\x1b\x1c\x1d\x1e\x1f" +
+	"\"():<>?@[\\]{}\x7f"
+
+// Return true for a Range of a single byte range with a start: "bytes=N-" or
+// "bytes=N-M".
+func isSimpleRange(value string) bool {
+	if !strings.HasPrefix(value, "bytes=") {
+		return false
+	}
+	bounds := strings.SplitN(value[len("bytes="):], "-", 2)
+	if len(bounds) != 2 || !isDigits(bounds[0]) || (bounds[1] != "" && !isDigits(bounds[1])) {
+		return false
+	}
+	return bounds[1] == "" || len(bounds[0]) < len(bounds[1]) ||
+		(len(bounds[0]) == len(bounds[1]) && bounds[0] <= bounds[1])
+}
+
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
@@ -0,0 +1,70 @@
+package revel
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestIsCORSSimple(t *testing.T) {
+	testCases := []struct {
+		method  string
+		headers map[string]string
+		simple  bool
+	}{
+		{"GET", nil, true},
+		{"HEAD", nil, true},
+		{"POST", nil, true},
+		{"PUT", nil, false},
+		{"DELETE", nil, false},
+		{"get", nil, false},
+
+		// Content-Type safelist.
+		{"POST", map[string]string{"Content-Type": "application/x-www-form-urlencoded"}, true},
+		{"POST", map[string]string{"Content-Type": "multipart/form-data; boundary=xyz"}, true},
+		{"POST", map[string]string{"Content-Type": "text/plain"}, true},
+		{"POST", map[string]string{"Content-Type": "Text/Plain; charset=utf-8"}, true},
+		{"POST", map[string]string{"Content-Type": " text/plain ;"}, true},
+		{"POST", map[string]string{"Content-Type": "application/json"}, false},
+		{"POST", map[string]string{"Content-Type": "text/plain+json"}, false},
+		{"POST", map[string]string{"Content-Type": "text/html"}, false},
+		{"POST", map[string]string{"Content-Type": ""}, false},
+		{"POST", map[string]string{"Content-Type": `text/plain; charset="utf-8"`}, false},
+		{"POST", map[string]string{"Content-Type": "text/plain; a=(b)"}, false},
+		{"POST", map[string]string{"Content-Type": "text/plain; x=" + strings.Repeat("a", 120)}, false},
+		{"GET", map[string]string{"Content-Type": "application/json"}, false},
+
+		// Other safelisted headers.
+		{"GET", map[string]string{"Accept": "application/json, */*;q=0.8", "Accept-Language": "en-US,en;q=0.9"}, true},
+		{"GET", map[string]string{"Accept": "text/html{}"}, false},
+		{"GET", map[string]string{"Accept-Language": "en_US"}, false},
+		{"GET", map[string]string{"Content-Language": "de-DE"}, true},
+		{"GET", map[string]string{"Range": "bytes=0-99"}, true},
+		{"GET", map[string]string{"Range": "bytes=100-"}, true},
+		{"GET", map[string]string{"Range": "bytes=-100"}, false},
+		{"GET", map[string]string{"Range": "bytes=0-1, 5-6"}, false},
+		{"GET", map[string]string{"Range": "bytes=99-0"}, false},
+
+		// Headers set by the browser or by proxies do not count.
+		{"GET", map[string]string{"Origin": "https://a.example", "Cookie": "a=b", "User-Agent": "x",
+			"Sec-Fetch-Mode": "cors", "X-Forwarded-For": "10.0.0.1", "Accept-Encoding": "gzip"}, true},
+		{"GET", map[string]string{"Authorization": "Bearer x"}, false},
+		{"GET", map[string]string{"X-Requested-With": "XMLHttpRequest"}, false},
+	}
+	for _, testCase := range testCases {
+		httpRequest, _ := http.NewRequest("GET", "/", nil)
+		httpRequest.Method = testCase.method
+		for name, value := range testCase.headers {
+			httpRequest.Header.Set(name, value)
+		}
+		if actual := NewRequest(httpRequest).IsCORSSimple(); actual != testCase.simple {
+			t.Errorf("%s %v: expected simple=%v", testCase.method, testCase.headers, testCase.simple)
+		}
+	}
+
+	httpRequest, _ := http.NewRequest("GET", "/", nil)
+	httpRequest.Header["Accept"] = []string{"text/html", "application/json"}
+	if NewRequest(httpRequest).IsCORSSimple() {
+		t.Errorf("Expected a repeated safelisted header to need a preflight")
+	}
+}
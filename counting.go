@@ -0,0 +1,80 @@
+package revel
+
+import (
+	"io"
+	"net/http"
+)
+
+// Return the number of body bytes written to the response, before any
+// compression.  (See BytesSent for the number sent to the client.)
+func (resp *Response) BytesWritten() int64 {
+	return resp.bytesWritten
+}
+
+// Return the number of body bytes sent to the client, after any compression.
+func (resp *Response) BytesSent() int64 {
+	return resp.bytesSent
+}
+
+// Return the status sent to the client, or Response.Status if the header has
+// not been sent yet.
+func (resp *Response) StatusCode() int {
+	if resp.statusSent != 0 {
+		return resp.statusSent
+	}
+	return resp.Status
+}
+
+// A ResponseWriter that records the status and body size of the response, as
+// sent to the client.  Copying a body with io.Copy (or ReadFrom) uses the
+// underlying writer's ReadFrom where it has one, e.g. for sendfile.
+type countingWriter struct {
+	http.ResponseWriter
+	resp *Response
+}
+
+// Return a countingWriter for the response around w, supporting the same
+// optional interfaces as w.
+func newCountingWriter(w http.ResponseWriter, resp *Response) http.ResponseWriter {
+	flusher, _ := w.(http.Flusher)
+	hijacker, _ := w.(http.Hijacker)
+	pusher, _ := w.(http.Pusher)
+	return withOptionalInterfaces(&countingWriter{w, resp}, flusher, hijacker, pusher)
+}
+
+func (w *countingWriter) WriteHeader(status int) {
+	if w.resp.statusSent == 0 && (status < 100 || status >= 200) {
+		w.resp.statusSent = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	if w.resp.statusSent == 0 {
+		w.resp.statusSent = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.count(int64(n))
+	return n, err
+}
+
+func (w *countingWriter) ReadFrom(src io.Reader) (int64, error) {
+	if w.resp.statusSent == 0 {
+		w.resp.statusSent = http.StatusOK
+	}
+	n, err := io.Copy(w.ResponseWriter, src)
+	w.count(n)
+	return n, err
+}
+
+func (w *countingWriter) count(n int64) {
+	w.resp.bytesSent += n
+	if !w.resp.compressing {
+		w.resp.bytesWritten += n
+	}
+}
+
+// Return the underlying writer, for http.ResponseController.
+func (w *countingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
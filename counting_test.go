@@ -0,0 +1,106 @@
+package revel
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBytesWritten(t *testing.T) {
+	defer func(size int) { CompressionMinSize = size }(CompressionMinSize)
+	CompressionMinSize = 10
+	body := strings.Repeat("hello world ", 100)
+
+	for _, compress := range []bool{false, true} {
+		httpRequest, _ := http.NewRequest("GET", "/", nil)
+		httpRequest.Header.Set("Accept-Encoding", "gzip")
+		recorder := httptest.NewRecorder()
+		resp := NewResponse(recorder)
+		if compress {
+			resp.Compress(NewRequest(httpRequest))
+		}
+		if resp.StatusCode() != 0 {
+			t.Errorf("compress=%v: expected no status before writing, got %d", compress, resp.StatusCode())
+		}
+		resp.WriteHeader(http.StatusCreated, "text/plain")
+		resp.Out.Write([]byte(body))
+		resp.Close()
+
+		if resp.StatusCode() != http.StatusCreated {
+			t.Errorf("compress=%v: expected status 201, got %d", compress, resp.StatusCode())
+		}
+		if resp.BytesWritten() != int64(len(body)) {
+			t.Errorf("compress=%v: expected %d bytes written, got %d", compress, len(body), resp.BytesWritten())
+		}
+		if resp.BytesSent() != int64(recorder.Body.Len()) {
+			t.Errorf("compress=%v: expected %d bytes sent, got %d", compress, recorder.Body.Len(), resp.BytesSent())
+		}
+		if compress == (resp.BytesSent() >= resp.BytesWritten()) {
+			t.Errorf("compress=%v: unexpected %d bytes sent for %d written", compress, resp.BytesSent(), resp.BytesWritten())
+		}
+	}
+}
+
+func TestCountingWriterFlush(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	resp := NewResponse(recorder)
+	resp.Out.Write([]byte("x"))
+	resp.Out.(http.Flusher).Flush()
+	if !recorder.Flushed {
+		t.Errorf("Expected the flush to reach the underlying writer")
+	}
+	if resp.StatusCode() != http.StatusOK {
+		t.Errorf("Expected an implicit 200, got %d", resp.StatusCode())
+	}
+}
+
+// A ResponseWriter with only the required methods, and a ReadFrom.
+type readerFromWriter struct {
+	header   http.Header
+	body     strings.Builder
+	readFrom bool
+}
+
+func (w *readerFromWriter) Header() http.Header         { return w.header }
+func (w *readerFromWriter) Write(b []byte) (int, error) { return w.body.Write(b) }
+func (w *readerFromWriter) WriteHeader(int)             {}
+
+func (w *readerFromWriter) ReadFrom(src io.Reader) (int64, error) {
+	w.readFrom = true
+	return io.Copy(&w.body, src)
+}
+
+func TestCountingWriterInterfaces(t *testing.T) {
+	w := &readerFromWriter{header: make(http.Header)}
+	resp := NewResponse(w)
+	if _, ok := resp.Out.(http.Flusher); ok {
+		t.Errorf("Expected no Flusher for a writer that can not flush")
+	}
+	if _, ok := resp.Out.(http.Hijacker); ok {
+		t.Errorf("Expected no Hijacker for a writer that can not hijack")
+	}
+	if _, ok := resp.Out.(http.Pusher); ok {
+		t.Errorf("Expected no Pusher for a writer that can not push")
+	}
+
+	n, err := io.Copy(resp.Out, io.LimitReader(strings.NewReader("hello"), 10))
+	if err != nil || n != 5 || !w.readFrom || w.body.String() != "hello" {
+		t.Errorf("Expected the copy to use ReadFrom, got %d, %v (ReadFrom: %v)", n, err, w.readFrom)
+	}
+	if resp.BytesWritten() != 5 || resp.BytesSent() != 5 || resp.StatusCode() != http.StatusOK {
+		t.Errorf("Expected 5 bytes counted with a 200, got %d/%d (%d)", resp.BytesWritten(), resp.BytesSent(), resp.StatusCode())
+	}
+
+	resp = NewResponse(&hijackableRecorder{httptest.NewRecorder(), nil})
+	if _, ok := resp.Out.(http.Flusher); !ok {
+		t.Errorf("Expected a Flusher")
+	}
+	if _, ok := resp.Out.(http.Hijacker); !ok {
+		t.Errorf("Expected a Hijacker")
+	}
+	if _, ok := resp.Out.(http.Pusher); ok {
+		t.Errorf("Expected no Pusher")
+	}
+}
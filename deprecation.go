@@ -0,0 +1,22 @@
+package revel
+
+import (
+	"net/http"
+	"time"
+)
+
+// Signal that the requested endpoint is deprecated, with the Deprecation and
+// Sunset headers (as HTTP-dates) and a Link to documentation about it with
+// rel="deprecation".  Zero times and an empty link are omitted.
+func (resp *Response) Deprecated(since time.Time, sunset time.Time, link string) {
+	header := resp.Out.Header()
+	if !since.IsZero() {
+		header.Set("Deprecation", since.UTC().Format(http.TimeFormat))
+	}
+	if !sunset.IsZero() {
+		header.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+	}
+	if link != "" {
+		header.Add("Link", "<"+link+`>; rel="deprecation"`)
+	}
+}
@@ -0,0 +1,24 @@
+package revel
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDeprecated(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.FixedZone("CET", 3600))
+	NewResponse(recorder).Deprecated(since, time.Time{}, "https://example.com/deprecation")
+
+	header := recorder.Header()
+	if actual := header.Get("Deprecation"); actual != "Sun, 31 Dec 2023 23:00:00 GMT" {
+		t.Errorf("Unexpected Deprecation header %q", actual)
+	}
+	if _, ok := header["Sunset"]; ok {
+		t.Errorf("Expected no Sunset header for a zero time")
+	}
+	if actual := header.Get("Link"); actual != `<https://example.com/deprecation>; rel="deprecation"` {
+		t.Errorf("Unexpected Link header %q", actual)
+	}
+}
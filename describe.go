@@ -0,0 +1,48 @@
+package revel
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// The media types of request bodies that the application accepts: those that
+// ParseParams decodes.  Apps that decode others (e.g. JSON bodies) should add
+// them, so that they are advertised by Response.Describe.
+var BodyMediaTypes = []string{"application/x-www-form-urlencoded", "multipart/form-data"}
+
+// A machine-readable description of a resource's capabilities, as written by
+// Response.Describe.
+type ResourceDescription struct {
+	XMLName  xml.Name `json:"-" xml:"resource"`
+	Methods  []string `json:"methods" xml:"method"`           // The allowed methods.
+	Accepts  []string `json:"accepts,omitempty" xml:"accept"` // Request body media types.  Defaults to BodyMediaTypes.
+	Produces []string `json:"produces" xml:"produce"`         // Representations.  Defaults to the registered formats.
+}
+
+// Answer an OPTIONS request with a description of the resource: the allowed
+// methods in the Allow header, the accepted body media types in the
+// Accept-Post and Accept-Patch headers (for resources that allow those
+// methods), and the description itself, in the request format, as the body.
+func (resp *Response) Describe(req *Request, resource ResourceDescription) error {
+	resp.setAllow(resource.Methods)
+	resource.Methods = splitHeaderList(resp.Out.Header()["Allow"])
+	if resource.Accepts == nil {
+		resource.Accepts = BodyMediaTypes
+	}
+	if resource.Produces == nil {
+		for _, format := range formats {
+			if len(format.MediaTypes) > 0 {
+				resource.Produces = append(resource.Produces, format.MediaTypes[0])
+			}
+		}
+	}
+
+	accepts := strings.Join(resource.Accepts, ", ")
+	if ContainsString(resource.Methods, "POST") && accepts != "" {
+		resp.Out.Header().Set("Accept-Post", accepts)
+	}
+	if ContainsString(resource.Methods, "PATCH") && accepts != "" {
+		resp.Out.Header().Set("Accept-Patch", accepts)
+	}
+	return resp.WriteNegotiated(req, resource)
+}
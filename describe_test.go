@@ -0,0 +1,39 @@
+package revel
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	err := NewResponse(recorder).Describe(newTestRequest("json"), ResourceDescription{
+		Methods: []string{"get", "POST", "GET"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header := recorder.Header()
+	if recorder.Code != http.StatusOK || header.Get("Allow") != "GET, POST" {
+		t.Errorf("Unexpected status %d, Allow %q", recorder.Code, header.Get("Allow"))
+	}
+	if actual := header.Get("Accept-Post"); actual != "application/x-www-form-urlencoded, multipart/form-data" {
+		t.Errorf("Unexpected Accept-Post %q", actual)
+	}
+	if _, ok := header["Accept-Patch"]; ok {
+		t.Errorf("Expected no Accept-Patch for a resource that does not allow PATCH")
+	}
+
+	var description ResourceDescription
+	if err := json.Unmarshal(recorder.Body.Bytes(), &description); err != nil {
+		t.Fatal(err)
+	}
+	produces := []string{"text/html", "application/xml", "text/plain", "application/json"}
+	if !reflect.DeepEqual(description.Methods, []string{"GET", "POST"}) || !reflect.DeepEqual(description.Produces, produces) {
+		t.Errorf("Unexpected description %+v", description)
+	}
+}
@@ -0,0 +1,39 @@
+package revel
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// The supported digest algorithms, by their registered names.
+var digestAlgorithms = map[string]func() hash.Hash{
+	"sha-256": sha256.New,
+	"sha-512": sha512.New,
+}
+
+// Set the digest of the response body, computed with the algorithm ("sha-256"
+// or "sha-512"), so that clients may verify it.  It is sent both as the
+// Content-Digest header of RFC 9530 and as the older Digest header of RFC 3230.
+//
+// The body must be the final bytes of the response, so it must be called
+// before the response is written.  A response with a digest is not compressed,
+// which would change the bytes that are sent.
+func (resp *Response) SetDigest(algorithm string, body []byte) error {
+	algorithm = strings.ToLower(algorithm)
+	newHash, ok := digestAlgorithms[algorithm]
+	if !ok {
+		return fmt.Errorf("unsupported digest algorithm %q", algorithm)
+	}
+	h := newHash()
+	h.Write(body)
+	sum := base64.StdEncoding.EncodeToString(h.Sum(nil))
+
+	header := resp.Out.Header()
+	header.Set("Content-Digest", algorithm+"=:"+sum+":")
+	header.Set("Digest", algorithm+"="+sum)
+	return nil
+}
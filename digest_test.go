@@ -0,0 +1,59 @@
+package revel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSetDigest(t *testing.T) {
+	testCases := []struct {
+		algorithm, contentDigest string
+	}{
+		{"sha-256", "sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:"},
+		{"SHA-512", "sha-512=:WZDPaVn/7XgHaAy8pmojAkGWoRx2UFChF41A2svX+TaPm+AbwAgBWnrIiYllu7BNNyealdVLvRwEmTHWXvJwew==:"},
+	}
+	for _, testCase := range testCases {
+		recorder := httptest.NewRecorder()
+		if err := NewResponse(recorder).SetDigest(testCase.algorithm, []byte(`{"hello": "world"}`)); err != nil {
+			t.Errorf("%s: %s", testCase.algorithm, err)
+			continue
+		}
+		if actual := recorder.Header().Get("Content-Digest"); actual != testCase.contentDigest {
+			t.Errorf("%s: expected Content-Digest %q, got %q", testCase.algorithm, testCase.contentDigest, actual)
+		}
+		legacy := strings.Replace(strings.TrimSuffix(testCase.contentDigest, ":"), "=:", "=", 1)
+		if actual := recorder.Header().Get("Digest"); actual != legacy {
+			t.Errorf("%s: expected Digest %q, got %q", testCase.algorithm, legacy, actual)
+		}
+	}
+
+	if err := NewResponse(httptest.NewRecorder()).SetDigest("md5", nil); err == nil {
+		t.Errorf("Expected an error for an unsupported algorithm")
+	}
+}
+
+func TestSetDigestSkipsCompression(t *testing.T) {
+	defer func(size int) { CompressionMinSize = size }(CompressionMinSize)
+	CompressionMinSize = 1
+
+	httpRequest, _ := http.NewRequest("GET", "/", nil)
+	httpRequest.Header.Set("Accept-Encoding", "gzip")
+	recorder := httptest.NewRecorder()
+	resp := NewResponse(recorder)
+	resp.Compress(NewRequest(httpRequest))
+
+	body := []byte("hello world")
+	resp.SetDigest("sha-256", body)
+	resp.WriteHeader(http.StatusOK, "text/plain")
+	resp.Out.Write(body)
+	resp.Close()
+
+	if encoding := recorder.Header().Get("Content-Encoding"); encoding != "" {
+		t.Errorf("Expected a digested body to be sent uncompressed, got Content-Encoding %q", encoding)
+	}
+	if recorder.Body.String() != "hello world" {
+		t.Errorf("Unexpected body %q", recorder.Body)
+	}
+}
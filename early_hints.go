@@ -0,0 +1,38 @@
+package revel
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+)
+
+var ErrHeaderWritten = errors.New("response header already written")
+
+// Send a 103 Early Hints response listing resources for the client to preload,
+// before the final response.  The links map each URL to the type of resource,
+// used as its "as" attribute.  (e.g. "/app.css" => "style")
+//
+// The Link headers are also left on the final response, so that the hints are
+// not lost when the writer (or the client) does not support informational
+// responses.  It must be called before the final response is written.
+func (resp *Response) EarlyHints(links map[string]string) error {
+	if resp.wroteHeader {
+		return ErrHeaderWritten
+	}
+	urls := make([]string, 0, len(links))
+	for url := range links {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	header := resp.Out.Header()
+	for _, url := range urls {
+		link := "<" + url + ">; rel=preload"
+		if as := links[url]; as != "" {
+			link += "; as=" + as
+		}
+		header.Add("Link", link)
+	}
+	resp.Out.WriteHeader(http.StatusEarlyHints)
+	return nil
+}
@@ -0,0 +1,46 @@
+package revel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"reflect"
+	"testing"
+)
+
+func TestEarlyHints(t *testing.T) {
+	var informational []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := NewResponse(w)
+		if err := resp.EarlyHints(map[string]string{"/app.js": "script", "/app.css": "style"}); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		resp.WriteHeader(http.StatusOK, "text/html")
+		if err := resp.EarlyHints(map[string]string{"/late.css": "style"}); err != ErrHeaderWritten {
+			t.Errorf("Expected ErrHeaderWritten, got %v", err)
+		}
+	}))
+	defer server.Close()
+
+	httpRequest, _ := http.NewRequest("GET", server.URL, nil)
+	httpRequest = httpRequest.WithContext(httptrace.WithClientTrace(httpRequest.Context(), &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			informational = append(informational, code)
+			return nil
+		},
+	}))
+	response, err := http.DefaultClient.Do(httpRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	if !reflect.DeepEqual(informational, []int{http.StatusEarlyHints}) {
+		t.Errorf("Expected a 103 response, got %v", informational)
+	}
+	expected := []string{"</app.css>; rel=preload; as=style", "</app.js>; rel=preload; as=script"}
+	if actual := response.Header["Link"]; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected the final response to keep the links %q, got %q", expected, actual)
+	}
+}
@@ -1,11 +1,85 @@
 package revel
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 	"runtime/debug"
 	"strings"
 )
 
+// Sentinel errors that Response.WriteError maps to HTTP status codes.
+// Applications may wrap these (fmt.Errorf("...: %w", ErrNotFound)) to add detail.
+var (
+	ErrNotFound             = errors.New("not found")
+	ErrForbidden            = errors.New("forbidden")
+	ErrValidation           = errors.New("validation failed")
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+)
+
+// Errors may implement this interface to choose their own HTTP status code.
+type HttpStatusError interface {
+	error
+	HttpStatus() int
+}
+
+// The error returned when a request body has a content type that can not be
+// decoded.  It matches ErrUnsupportedMediaType, and WriteError renders it with
+// Response.UnsupportedMediaType.
+type UnsupportedMediaTypeError struct {
+	ContentType string   // The content type of the request.
+	Accepted    []string // The media types that could have been decoded.
+}
+
+func (e *UnsupportedMediaTypeError) Error() string {
+	return fmt.Sprintf("unsupported media type %q (accepted: %s)",
+		e.ContentType, strings.Join(e.Accepted, ", "))
+}
+
+func (e *UnsupportedMediaTypeError) Is(target error) bool {
+	return target == ErrUnsupportedMediaType
+}
+
+type errorStatus struct {
+	target error
+	status int
+}
+
+var errorStatuses = []errorStatus{
+	{ErrNotFound, http.StatusNotFound},
+	{ErrForbidden, http.StatusForbidden},
+	{ErrValidation, http.StatusBadRequest},
+	{ErrUnsupportedMediaType, http.StatusUnsupportedMediaType},
+	{ErrBodyTooLarge, http.StatusRequestEntityTooLarge},
+	{ErrBodyLengthMismatch, http.StatusBadRequest},
+	{ErrTooManyParts, http.StatusRequestEntityTooLarge},
+	{ErrUnsupportedCharset, http.StatusUnsupportedMediaType},
+	{ErrMalformedJSON, http.StatusBadRequest},
+	{ErrUnknownJSONField, http.StatusUnprocessableEntity},
+	{ErrMalformedPatch, http.StatusBadRequest},
+	{ErrPatchConflict, http.StatusConflict},
+}
+
+// Register the HTTP status code used for errors that match the given target
+// (according to errors.Is).  Later registrations take precedence.
+func RegisterErrorStatus(target error, status int) {
+	errorStatuses = append(errorStatuses, errorStatus{target, status})
+}
+
+// Return the HTTP status code for the given error, if it is known.
+func errorStatusCode(err error) (int, bool) {
+	var statusErr HttpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.HttpStatus(), true
+	}
+	for i := len(errorStatuses) - 1; i >= 0; i-- {
+		if errors.Is(err, errorStatuses[i].target) {
+			return errorStatuses[i].status, true
+		}
+	}
+	return 0, false
+}
+
 // An error description, used as an argument to the error template.
 type Error struct {
 	SourceType               string   // The type of source that failed to build.
@@ -0,0 +1,52 @@
+package revel
+
+import (
+	"net/http"
+	"strings"
+)
+
+var (
+	// The prefix of the request headers that set feature flags.
+	// e.g. "X-Feature-" for X-Feature-New-Checkout: on
+	// Configured with features.header.prefix in app.conf; "" disables them.
+	FeatureHeaderPrefix = "X-Feature-"
+
+	// The prefix of the cookies that set feature flags, which are consulted when
+	// there is no header.  e.g. "feature_" for feature_new-checkout=on
+	// Configured with features.cookie.prefix in app.conf; "" (the default)
+	// disables them.
+	FeatureCookiePrefix = ""
+)
+
+func init() {
+	OnAppStart(func() {
+		FeatureHeaderPrefix = Config.StringDefault("features.header.prefix", FeatureHeaderPrefix)
+		FeatureCookiePrefix = Config.StringDefault("features.cookie.prefix", FeatureCookiePrefix)
+	})
+}
+
+// Return the value of the named feature flag, from its header (see
+// FeatureHeaderPrefix) or else its cookie (see FeatureCookiePrefix).
+// The flag is enabled if it is set to anything other than "", "0", "false",
+// "off" or "no" (in any case).
+func (r *Request) FeatureFlag(name string) (value string, enabled bool) {
+	found := false
+	if FeatureHeaderPrefix != "" {
+		if values, ok := r.Header[http.CanonicalHeaderKey(FeatureHeaderPrefix+name)]; ok && len(values) > 0 {
+			value, found = strings.TrimSpace(values[0]), true
+		}
+	}
+	if !found && FeatureCookiePrefix != "" {
+		if cookie, err := r.Cookie(FeatureCookiePrefix + name); err == nil {
+			value, found = strings.TrimSpace(cookie.Value), true
+		}
+	}
+	if !found {
+		return "", false
+	}
+	switch strings.ToLower(value) {
+	case "", "0", "false", "off", "no":
+		return value, false
+	}
+	return value, true
+}
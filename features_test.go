@@ -0,0 +1,37 @@
+package revel
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestFeatureFlag(t *testing.T) {
+	defer func() { FeatureCookiePrefix = "" }()
+	FeatureCookiePrefix = "feature_"
+
+	httpRequest, _ := http.NewRequest("GET", "/", nil)
+	httpRequest.Header.Set("X-Feature-Checkout", "v2")
+	httpRequest.Header.Set("X-Feature-Search", "off")
+	httpRequest.Header.Set("Cookie", "feature_search=on; feature_beta=1")
+	req := NewRequest(httpRequest)
+
+	testCases := []struct {
+		name, value string
+		enabled     bool
+	}{
+		{"checkout", "v2", true},
+		{"search", "off", false},
+		{"beta", "1", true},
+		{"missing", "", false},
+	}
+	for _, testCase := range testCases {
+		if value, enabled := req.FeatureFlag(testCase.name); value != testCase.value || enabled != testCase.enabled {
+			t.Errorf("%s: expected %q %v, got %q %v", testCase.name, testCase.value, testCase.enabled, value, enabled)
+		}
+	}
+
+	FeatureCookiePrefix = ""
+	if _, enabled := req.FeatureFlag("beta"); enabled {
+		t.Errorf("Expected cookies to be ignored without a prefix")
+	}
+}
@@ -0,0 +1,42 @@
+package revel
+
+import (
+	"strings"
+)
+
+// Return the Sec-Fetch-Site header, lower-cased: the relation of the request's
+// initiator to its target.  One of "same-origin", "same-site", "cross-site" or
+// "none" (for user-initiated navigations), or "" if the client did not send it
+// (e.g. older browsers, and non-browser clients).
+func (r *Request) FetchSite() string {
+	return strings.ToLower(strings.TrimSpace(r.Header.Get("Sec-Fetch-Site")))
+}
+
+// Return the Sec-Fetch-Mode header, lower-cased: the mode of the request.
+// e.g. "navigate", "cors", "no-cors", "same-origin" or "websocket", or "" if
+// the client did not send it.
+func (r *Request) FetchMode() string {
+	return strings.ToLower(strings.TrimSpace(r.Header.Get("Sec-Fetch-Mode")))
+}
+
+// Return the Sec-Fetch-Dest header, lower-cased: how the response will be
+// used.  e.g. "document", "image", "script" or "empty", or "" if the client
+// did not send it.
+func (r *Request) FetchDest() string {
+	return strings.ToLower(strings.TrimSpace(r.Header.Get("Sec-Fetch-Dest")))
+}
+
+// Return true if the Sec-Fetch-User header says that the request was triggered
+// by the user (e.g. by clicking a link).  False if the client did not send it.
+func (r *Request) FetchUser() bool {
+	return strings.TrimSpace(r.Header.Get("Sec-Fetch-User")) == "?1"
+}
+
+// Return true if the browser says the request came from the same site (or
+// origin) as its target.  It is false for cross-site and user-initiated
+// requests, and for clients that do not send Sec-Fetch-Site, so policies that
+// reject cross-site requests should allow requests without it.
+func (r *Request) IsSameSiteFetch() bool {
+	site := r.FetchSite()
+	return site == "same-origin" || site == "same-site"
+}
@@ -0,0 +1,39 @@
+package revel
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestFetchMetadata(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/", nil)
+	httpRequest.Header.Set("Sec-Fetch-Site", "Same-Site")
+	httpRequest.Header.Set("Sec-Fetch-Mode", "navigate")
+	httpRequest.Header.Set("Sec-Fetch-Dest", "document")
+	httpRequest.Header.Set("Sec-Fetch-User", "?1")
+	req := NewRequest(httpRequest)
+	if req.FetchSite() != "same-site" || req.FetchMode() != "navigate" || req.FetchDest() != "document" || !req.FetchUser() {
+		t.Errorf("Unexpected fetch metadata %q %q %q %v", req.FetchSite(), req.FetchMode(), req.FetchDest(), req.FetchUser())
+	}
+
+	testCases := map[string]bool{
+		"same-origin": true,
+		"same-site":   true,
+		"cross-site":  false,
+		"none":        false,
+		"":            false,
+	}
+	for site, expected := range testCases {
+		httpRequest, _ := http.NewRequest("GET", "/", nil)
+		if site != "" {
+			httpRequest.Header.Set("Sec-Fetch-Site", site)
+		}
+		req := NewRequest(httpRequest)
+		if actual := req.IsSameSiteFetch(); actual != expected {
+			t.Errorf("%q: expected %v", site, expected)
+		}
+		if req.FetchUser() {
+			t.Errorf("%q: expected FetchUser to be false without the header", site)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package revel
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Write the value as JSON, keeping only the fields the client selected with the
+// given query parameter.  (e.g. "?fields=id,name,author.name")
+//
+// The selection applies to the top-level object, or to each object of a
+// top-level array; nested fields are selected with dot notation.  Unknown
+// field names are ignored, and if the parameter is absent the value is written
+// in full.  The members of filtered objects are written in sorted order.
+func (resp *Response) WriteJSONFiltered(req *Request, v interface{}, fieldsParam string) error {
+	selection := parseFieldSelection(req.URL.Query().Get(fieldsParam))
+	if len(selection) == 0 {
+		return resp.WriteJSON(req, v)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		resp.WriteError(req, err)
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err = decoder.Decode(&decoded); err != nil {
+		resp.WriteError(req, err)
+		return err
+	}
+	return resp.WriteJSON(req, selection.filter(decoded))
+}
+
+// The selected fields, each mapped to its selected sub-fields (empty to select
+// the whole field).
+type fieldSelection map[string]fieldSelection
+
+// Parse a comma-separated list of (dotted) field names.
+func parseFieldSelection(fields string) fieldSelection {
+	selection := fieldSelection{}
+	for _, field := range strings.Split(fields, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		node := selection
+		for _, name := range strings.Split(field, ".") {
+			if node[name] == nil {
+				node[name] = fieldSelection{}
+			}
+			node = node[name]
+		}
+	}
+	return selection
+}
+
+// Prune the decoded JSON value to the selected fields.
+func (s fieldSelection) filter(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		filtered := make(map[string]interface{}, len(s))
+		for name, sub := range s {
+			if member, ok := value[name]; ok {
+				if len(sub) > 0 {
+					member = sub.filter(member)
+				}
+				filtered[name] = member
+			}
+		}
+		return filtered
+	case []interface{}:
+		for i, element := range value {
+			value[i] = s.filter(element)
+		}
+		return value
+	}
+	return v
+}
@@ -0,0 +1,37 @@
+package revel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteJSONFiltered(t *testing.T) {
+	type author struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	type book struct {
+		Id     int64  `json:"id"`
+		Title  string `json:"title"`
+		Author author `json:"author"`
+	}
+	books := []book{{9007199254740993, "Dune", author{"Frank", "frank@example.com"}}}
+
+	testCases := map[string]string{
+		"":                                "[{\"id\":9007199254740993,\"title\":\"Dune\",\"author\":{\"name\":\"Frank\",\"email\":\"frank@example.com\"}}]",
+		"?fields=id,title":                "[{\"id\":9007199254740993,\"title\":\"Dune\"}]",
+		"?fields=title,author.name,bogus": "[{\"author\":{\"name\":\"Frank\"},\"title\":\"Dune\"}]",
+		"?fields=title.x":                 "[{\"title\":\"Dune\"}]",
+	}
+	for query, expected := range testCases {
+		httpRequest, _ := http.NewRequest("GET", "/books"+query, nil)
+		recorder := httptest.NewRecorder()
+		if err := NewResponse(recorder).WriteJSONFiltered(NewRequest(httpRequest), books, "fields"); err != nil {
+			t.Errorf("%s: unexpected error: %s", query, err)
+		}
+		if actual := recorder.Body.String(); actual != expected {
+			t.Errorf("%s: expected %s, got %s", query, expected, actual)
+		}
+	}
+}
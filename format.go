@@ -0,0 +1,225 @@
+package revel
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// A Format is a representation that requests may negotiate (see ResolveFormat).
+type Format struct {
+	Name       string   // The short name, as found in Request.Format.  e.g. "json"
+	MediaTypes []string // The media types of this format.  e.g. "application/json"
+	Suffix     string   // The template file suffix.  Defaults to the Name.
+
+	// Marshal values for Response.WriteNegotiated, or nil if values can not be
+	// written in this format.  e.g. json.Marshal
+	Marshal func(v interface{}) ([]byte, error)
+
+	// The status of successful responses written by Response.WriteNegotiated,
+	// when the handler has not set one.  Defaults to 200.
+	Status int
+}
+
+// The registered formats, in order of server preference.
+var formats = []*Format{
+	{Name: "html", MediaTypes: []string{"text/html", "application/xhtml+xml"}},
+	{Name: "xml", MediaTypes: []string{"application/xml", "text/xml"}, Marshal: xml.Marshal},
+	{Name: "txt", MediaTypes: []string{"text/plain"}, Marshal: marshalText},
+	{Name: "json", MediaTypes: []string{"application/json", "text/javascript"}, Marshal: json.Marshal},
+}
+
+// Marshal a value as text, with fmt.Sprint.
+func marshalText(v interface{}) ([]byte, error) {
+	return []byte(fmt.Sprint(v)), nil
+}
+
+// Register a format that requests may negotiate.
+// A format with the same name as an existing one replaces it (keeping its
+// position); otherwise, it is added after the existing formats.
+// e.g. to marshal JSON with a faster library:
+//
+//     json := *revel.LookupFormat("json")
+//     json.Marshal = fastjson.Marshal
+//     revel.RegisterFormat(&json)
+func RegisterFormat(format *Format) {
+	for i, f := range formats {
+		if f.Name == format.Name {
+			formats[i] = format
+			return
+		}
+	}
+	formats = append(formats, format)
+}
+
+// Return the registered format with the given name, or nil if there is none.
+func LookupFormat(name string) *Format {
+	for _, f := range formats {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Check that every registered format can be negotiated and written: that it
+// has a name, at least one valid (non-wildcard) media type, and a Marshal func.
+// (The html format is exempt from the last, as it is rendered by templates.)
+// The error lists every problem found.  Call it from an OnAppStart hook, after
+// the app's formats are registered, to catch misconfiguration early.
+func ValidateFormatRegistry() error {
+	var problems []string
+	for i, f := range formats {
+		name := f.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i)
+			problems = append(problems, fmt.Sprintf("format %s has no name", name))
+		}
+		if len(f.MediaTypes) == 0 {
+			problems = append(problems, fmt.Sprintf("format %s has no media types", name))
+		}
+		for _, mediaType := range f.MediaTypes {
+			if mt, ok := parseMediaRange(mediaType); !ok || mt.Type == "*" || mt.Subtype == "*" {
+				problems = append(problems, fmt.Sprintf("format %s has an invalid media type %q", name, mediaType))
+			}
+		}
+		if f.Marshal == nil && f.Name != "html" {
+			problems = append(problems, fmt.Sprintf("format %s has no Marshal func", name))
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New("invalid format registry: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Return the template file suffix for the request format.
+// e.g. Users/Show.<suffix>
+func (r *Request) TemplateSuffix() string {
+	if format := LookupFormat(r.Format); format != nil && format.Suffix != "" {
+		return format.Suffix
+	}
+	return r.Format
+}
+
+var (
+	// If true, each request records how its format was negotiated, for debugging.
+	// (see Request.NegotiationTrace)  Configured with results.negotiation.trace.
+	NegotiationDebug = false
+
+	// The query parameter that explicitly selects the request format, overriding
+	// the Accept header.  (e.g. "?format=json")  Values that are not registered
+	// formats are ignored.  Configured with results.format.param; set it to ""
+	// to disable the override.
+	FormatParam = "format"
+
+	// The names of the formats that the server prefers, in order, when the
+	// client has no preference between them (e.g. "Accept: */*", or no Accept
+	// header).  The first is also used when no format is acceptable.  Formats
+	// that are not listed follow in registration order.  e.g. "json", "html"
+	// for an API service.  Configured with results.format.preference.
+	FormatPreference []string
+
+	// If true, clients must say which formats they accept: a request with no
+	// Accept header (or format parameter), or one that accepts none of the
+	// registered formats, is answered with 406 Not Acceptable instead of being
+	// given the most preferred format ("html" by default).  For API services.
+	// Configured with results.format.requireaccept.
+	RequireAccept = false
+)
+
+func init() {
+	OnAppStart(func() {
+		NegotiationDebug = Config.BoolDefault("results.negotiation.trace", false)
+		FormatParam = Config.StringDefault("results.format.param", FormatParam)
+		FormatPreference = splitConfigList(Config.StringDefault("results.format.preference", ""))
+		RequireAccept = Config.BoolDefault("results.format.requireaccept", false)
+	})
+}
+
+// Return the registered formats in order of server preference: those named by
+// FormatPreference first, then the rest in registration order.
+func preferredFormats() []*Format {
+	if len(FormatPreference) == 0 {
+		return formats
+	}
+	ordered := make([]*Format, 0, len(formats))
+	for _, name := range FormatPreference {
+		if f := LookupFormat(name); f != nil && !containsFormat(ordered, f) {
+			ordered = append(ordered, f)
+		}
+	}
+	for _, f := range formats {
+		if !containsFormat(ordered, f) {
+			ordered = append(ordered, f)
+		}
+	}
+	return ordered
+}
+
+func containsFormat(formats []*Format, format *Format) bool {
+	for _, f := range formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the format used when no registered format is acceptable: the most
+// preferred one, or "html".
+func fallbackFormat() string {
+	for _, name := range FormatPreference {
+		if LookupFormat(name) != nil {
+			return name
+		}
+	}
+	return "html"
+}
+
+// Return the format named by the FormatParam query parameter, if it is a
+// registered one.
+func formatFromParam(req *http.Request) (string, bool) {
+	if FormatParam == "" || req.URL == nil {
+		return "", false
+	}
+	name := strings.ToLower(strings.TrimSpace(req.URL.Query().Get(FormatParam)))
+	if name == "" || LookupFormat(name) == nil {
+		return "", false
+	}
+	return name, true
+}
+
+// A record of how the request format was resolved from the Accept header.
+type NegotiationTrace struct {
+	Accept     string                 // The Accept header, as sent.
+	Candidates []NegotiationCandidate // Every media type of every registered format.
+	Format     string                 // The resolved format.
+	Rule       string                 // Why that format was chosen.
+}
+
+// A media type considered during negotiation, with the quality the client gave
+// it and the precedence of the media range that matched it.
+type NegotiationCandidate struct {
+	Format, MediaType string
+	Quality           float32
+	Precedence        int
+}
+
+func (t *NegotiationTrace) String() string {
+	output := bytes.NewBufferString(fmt.Sprintf("Accept: %q => %s (%s)", t.Accept, t.Format, t.Rule))
+	for _, candidate := range t.Candidates {
+		output.WriteString(fmt.Sprintf("\n  %s %s (%1.3f)", candidate.Format, candidate.MediaType, candidate.Quality))
+	}
+	return output.String()
+}
+
+// Return the record of how the request format was negotiated.
+// Returns nil unless NegotiationDebug was on when the request arrived.
+func (r *Request) NegotiationTrace() *NegotiationTrace {
+	return r.negotiationTrace
+}
@@ -0,0 +1,76 @@
+package revel
+
+import (
+	"testing"
+)
+
+func TestRegisterFormat(t *testing.T) {
+	defer func(registered []*Format) { formats = registered }(append([]*Format{}, formats...))
+
+	RegisterFormat(&Format{Name: "csv", MediaTypes: []string{"text/csv"}})
+	RegisterFormat(&Format{Name: "txt", MediaTypes: []string{"text/plain"}, Suffix: "text"})
+
+	if format := ResolveFormat(acceptRequest("text/csv, text/*;q=0.5")); format != "csv" {
+		t.Errorf("Expected csv, got %s", format)
+	}
+	if format := formats[2]; format.Name != "txt" {
+		t.Errorf("Expected txt to keep its position, got %s", format.Name)
+	}
+
+	testCases := map[string]string{
+		"html":  "html",
+		"csv":   "csv",
+		"txt":   "text",
+		"other": "other",
+	}
+	for format, expected := range testCases {
+		req := newTestRequest(format)
+		if actual := req.TemplateSuffix(); actual != expected {
+			t.Errorf("%s: expected suffix %s, got %s", format, expected, actual)
+		}
+	}
+}
+
+func TestNegotiationTrace(t *testing.T) {
+	if trace := NewRequest(acceptRequest("application/json")).NegotiationTrace(); trace != nil {
+		t.Errorf("Expected no trace while debugging is off, got %s", trace)
+	}
+
+	NegotiationDebug = true
+	defer func() { NegotiationDebug = false }()
+
+	testCases := map[string]string{
+		"":                      "no Accept header: using the first registered format",
+		"image/png":             "no registered format is acceptable: using the default",
+		"*/*":                   "equal quality and precedence: using the first registered format",
+		"application/json, */*": "equal quality: using the most specific media range",
+		"application/json":      "highest quality",
+	}
+	for accept, expected := range testCases {
+		trace := NewRequest(acceptRequest(accept)).NegotiationTrace()
+		if trace == nil {
+			t.Fatal("Expected a trace")
+		}
+		if trace.Rule != expected {
+			t.Errorf("%q: expected rule %q, got %q", accept, expected, trace.Rule)
+		}
+		if len(trace.Candidates) != 7 {
+			t.Errorf("%q: expected 7 candidates, got %d", accept, len(trace.Candidates))
+		}
+	}
+}
+
+func TestValidateFormatRegistry(t *testing.T) {
+	defer func(registered []*Format) { formats = registered }(append([]*Format{}, formats...))
+	if err := ValidateFormatRegistry(); err != nil {
+		t.Errorf("Expected the default formats to be valid, got %s", err)
+	}
+
+	RegisterFormat(&Format{Name: "csv", MediaTypes: []string{"text/csv"}})
+	RegisterFormat(&Format{Name: "any", MediaTypes: []string{"*/*"}, Marshal: marshalText})
+	err := ValidateFormatRegistry()
+	expected := `invalid format registry: format csv has no Marshal func; format any has an invalid media type "*/*"`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+}
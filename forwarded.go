@@ -0,0 +1,186 @@
+package revel
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// The proxies whose X-Forwarded-* headers are trusted, as IP addresses or CIDR
+// ranges.  Headers from any other client are ignored.
+// Configured with http.proxies (a comma-separated list) in app.conf.
+var TrustedProxies []string
+
+func init() {
+	OnAppStart(func() {
+		TrustedProxies = splitConfigList(Config.StringDefault("http.proxies", ""))
+	})
+}
+
+// Return true if the request came directly from one of the TrustedProxies.
+func (r *Request) fromTrustedProxy() bool {
+	if len(TrustedProxies) == 0 {
+		return false
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, proxy := range TrustedProxies {
+		if strings.Contains(proxy, "/") {
+			if _, network, err := net.ParseCIDR(proxy); err == nil && network.Contains(ip) {
+				return true
+			}
+		} else if proxyIP := net.ParseIP(proxy); proxyIP != nil && proxyIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the first value of a forwarding header, if the request came from a
+// trusted proxy.  (Proxies append to the list, so the first is the client's.)
+func (r *Request) forwardedHeader(name string) string {
+	if !r.fromTrustedProxy() {
+		return ""
+	}
+	if values := splitHeaderList(r.Header[name]); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Return the scheme the client used: "http" or "https".
+// X-Forwarded-Proto is honored from TrustedProxies.
+func (r *Request) Scheme() string {
+	if proto := strings.ToLower(r.forwardedHeader("X-Forwarded-Proto")); proto == "http" || proto == "https" {
+		return proto
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// Return the host (and port, if any) the client requested, lower-cased.
+// X-Forwarded-Host and X-Forwarded-Port are honored from TrustedProxies.  (The
+// Host field is the Host header, as received.)
+func (r *Request) ExternalHost() string {
+	host := r.forwardedHeader("X-Forwarded-Host")
+	if host == "" {
+		host = r.Request.Host
+	}
+	host = strings.ToLower(host)
+	if port := r.forwardedPort(); port != 0 {
+		host = net.JoinHostPort(hostname(host), strconv.Itoa(port))
+	}
+	return host
+}
+
+// Return the port the client connected to: the X-Forwarded-Port from
+// TrustedProxies, or else the port of the Host header, or else the default for
+// the Scheme (80 or 443).
+func (r *Request) Port() int {
+	if port := r.forwardedPort(); port != 0 {
+		return port
+	}
+	if _, port, err := net.SplitHostPort(r.ExternalHost()); err == nil {
+		if port, err := strconv.Atoi(port); err == nil && port > 0 && port < 65536 {
+			return port
+		}
+	}
+	if r.Scheme() == "https" {
+		return 443
+	}
+	return 80
+}
+
+// Return the X-Forwarded-Port from TrustedProxies, or 0 if there is none.
+func (r *Request) forwardedPort() int {
+	port, err := strconv.Atoi(r.forwardedHeader("X-Forwarded-Port"))
+	if err != nil || port <= 0 || port >= 65536 {
+		return 0
+	}
+	return port
+}
+
+// Return the path prefix that a proxy stripped from the request path, from the
+// X-Forwarded-Prefix header of TrustedProxies, without a trailing slash.
+// e.g. "/api"  It is "" if there is none, or if it is not a plain path.
+func (r *Request) ForwardedPrefix() string {
+	prefix := strings.TrimRight(r.forwardedHeader("X-Forwarded-Prefix"), "/")
+	if !strings.HasPrefix(prefix, "/") || strings.HasPrefix(prefix, "//") ||
+		strings.ContainsAny(prefix, "\\?#") {
+		return ""
+	}
+	for _, c := range prefix {
+		if c < 0x20 || c == 0x7f {
+			return ""
+		}
+	}
+	for _, segment := range strings.Split(prefix, "/") {
+		if segment == "." || segment == ".." {
+			return ""
+		}
+	}
+	return prefix
+}
+
+// Return the URL of the root of the site, as the client sees it, with the port
+// if it is not the default for the scheme, and any ForwardedPrefix.
+// e.g. "https://example.com", "http://example.com:8080/api"
+func (r *Request) BaseURL() string {
+	return r.siteURL() + r.ForwardedPrefix()
+}
+
+// Return the scheme and host of BaseURL, without the ForwardedPrefix.
+func (r *Request) siteURL() string {
+	scheme := r.Scheme()
+	name, port := splitHostPort(r.ExternalHost(), scheme)
+	if port != "" {
+		return scheme + "://" + net.JoinHostPort(name, port)
+	}
+	if strings.Contains(name, ":") && !strings.HasPrefix(name, "[") {
+		name = "[" + name + "]"
+	}
+	return scheme + "://" + name
+}
+
+// Return the path (with any query) of a URL in the app as the client sees it:
+// under the ForwardedPrefix, unless it is already under it (e.g. a "next"
+// parameter taken from the URL that the client sees).  The request may be nil.
+func (r *Request) externalPath(requestURI string) string {
+	if r == nil {
+		return requestURI
+	}
+	prefix := r.ForwardedPrefix()
+	path := strings.SplitN(requestURI, "?", 2)[0]
+	if prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/") {
+		return requestURI
+	}
+	return prefix + requestURI
+}
+
+// Return the host without its port (and any brackets of an IPv6 address).
+func hostname(host string) string {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		return name
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+}
+
+// Split the port from a host, dropping it if it is the default for the scheme.
+func splitHostPort(host, scheme string) (hostname, port string) {
+	hostname, port, err := net.SplitHostPort(host)
+	if err != nil {
+		return host, ""
+	}
+	if (scheme == "http" && port == "80") || (scheme == "https" && port == "443") {
+		port = ""
+	}
+	return hostname, port
+}
@@ -0,0 +1,149 @@
+package revel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func forwardedRequest(remoteAddr, host string, headers map[string]string) *Request {
+	httpRequest, _ := http.NewRequest("GET", "http://"+host+"/path?q=1", nil)
+	httpRequest.RemoteAddr = remoteAddr
+	for name, value := range headers {
+		httpRequest.Header.Set(name, value)
+	}
+	return NewRequest(httpRequest)
+}
+
+func TestForwardedHost(t *testing.T) {
+	defer func() { TrustedProxies = nil }()
+	TrustedProxies = []string{"10.0.0.0/8", "192.168.1.1"}
+	forwarded := map[string]string{"X-Forwarded-Host": "Example.com, proxy.internal", "X-Forwarded-Proto": "https"}
+
+	testCases := []struct {
+		remoteAddr, host, expected string
+	}{
+		{"10.1.2.3:1234", "example.com", "https://example.com"},
+		{"192.168.1.1:1234", "example.com", "https://example.com"},
+		{"203.0.113.9:1234", "internal:9000", "http://internal:9000"},
+	}
+	for _, testCase := range testCases {
+		req := forwardedRequest(testCase.remoteAddr, "internal:9000", forwarded)
+		if actual := req.BaseURL(); actual != testCase.expected {
+			t.Errorf("%s: expected %s, got %s", testCase.remoteAddr, testCase.expected, actual)
+		}
+		if actual := req.ExternalHost(); actual != testCase.host {
+			t.Errorf("%s: expected the host %s, got %s", testCase.remoteAddr, testCase.host, actual)
+		}
+		if req.Host != "internal:9000" {
+			t.Errorf("%s: expected the Host field to be left as sent, got %s", testCase.remoteAddr, req.Host)
+		}
+	}
+}
+
+func TestCanonicalHostRedirect(t *testing.T) {
+	testCases := []struct {
+		host, canonical, expected string
+	}{
+		{"www.example.com", "example.com", "http://example.com/path?q=1"},
+		{"Example.com", "example.com", ""},
+		{"example.com:9000", "example.com", ""},
+		{"example.com:9000", "example.com:8080", "http://example.com:8080/path?q=1"},
+		{"example.com:80", "example.com:80", ""},
+		{"example.com", "www.example.com", "http://www.example.com/path?q=1"},
+	}
+	for _, testCase := range testCases {
+		recorder := httptest.NewRecorder()
+		req := forwardedRequest("203.0.113.9:1234", testCase.host, nil)
+		redirected := NewResponse(recorder).CanonicalHostRedirect(req, testCase.canonical)
+		if location := recorder.Header().Get("Location"); location != testCase.expected || redirected != (testCase.expected != "") {
+			t.Errorf("%s => %s: expected %q, got %q", testCase.host, testCase.canonical, testCase.expected, location)
+		} else if redirected && recorder.Code != http.StatusMovedPermanently {
+			t.Errorf("%s: expected a 301, got %d", testCase.host, recorder.Code)
+		}
+	}
+}
+
+func TestForwardedPort(t *testing.T) {
+	defer func() { TrustedProxies = nil }()
+	TrustedProxies = []string{"10.0.0.0/8"}
+
+	testCases := []struct {
+		remoteAddr, host string
+		headers          map[string]string
+		port             int
+		baseURL          string
+	}{
+		{"10.1.2.3:1234", "internal:9000", map[string]string{"X-Forwarded-Port": "8443", "X-Forwarded-Proto": "https"}, 8443, "https://internal:8443"},
+		{"10.1.2.3:1234", "internal:9000", map[string]string{"X-Forwarded-Port": "443", "X-Forwarded-Proto": "https", "X-Forwarded-Host": "example.com"}, 443, "https://example.com"},
+		{"10.1.2.3:1234", "internal:9000", map[string]string{"X-Forwarded-Port": "bogus"}, 9000, "http://internal:9000"},
+		{"203.0.113.9:1234", "internal:9000", map[string]string{"X-Forwarded-Port": "8443"}, 9000, "http://internal:9000"},
+		{"203.0.113.9:1234", "example.com", nil, 80, "http://example.com"},
+		{"203.0.113.9:1234", "example.com:80", nil, 80, "http://example.com"},
+		{"10.1.2.3:1234", "[::1]", map[string]string{"X-Forwarded-Port": "8080"}, 8080, "http://[::1]:8080"},
+		{"10.1.2.3:1234", "[::1]:80", nil, 80, "http://[::1]"},
+	}
+	for _, testCase := range testCases {
+		req := forwardedRequest(testCase.remoteAddr, testCase.host, testCase.headers)
+		if actual := req.Port(); actual != testCase.port {
+			t.Errorf("%+v: expected port %d, got %d", testCase, testCase.port, actual)
+		}
+		if actual := req.BaseURL(); actual != testCase.baseURL {
+			t.Errorf("%+v: expected base URL %s, got %s", testCase, testCase.baseURL, actual)
+		}
+	}
+}
+
+func TestForwardedPrefix(t *testing.T) {
+	defer func() { TrustedProxies = nil }()
+	TrustedProxies = []string{"10.0.0.0/8"}
+
+	testCases := []struct {
+		remoteAddr, prefix, expected string
+	}{
+		{"10.1.2.3:1234", "/api/", "/api"},
+		{"10.1.2.3:1234", "/a/b", "/a/b"},
+		{"10.1.2.3:1234", "api", ""},
+		{"10.1.2.3:1234", "//evil.com", ""},
+		{"10.1.2.3:1234", "/api/../admin", ""},
+		{"10.1.2.3:1234", "/api?x", ""},
+		{"203.0.113.9:1234", "/api", ""},
+	}
+	for _, testCase := range testCases {
+		req := forwardedRequest(testCase.remoteAddr, "example.com", map[string]string{"X-Forwarded-Prefix": testCase.prefix})
+		if actual := req.ForwardedPrefix(); actual != testCase.expected {
+			t.Errorf("%+v: got %q", testCase, actual)
+		}
+		if actual := req.BaseURL(); actual != "http://example.com"+testCase.expected {
+			t.Errorf("%+v: unexpected base URL %s", testCase, actual)
+		}
+	}
+
+	req := forwardedRequest("10.1.2.3:1234", "example.com", map[string]string{"X-Forwarded-Prefix": "/api"})
+	for target, expected := range map[string]string{
+		"login":            "/api/login",
+		"/orders":          "/api/orders",
+		"/apiary":          "/api/apiary",
+		"/api/orders?id=1": "/api/orders?id=1",
+		"/api":             "/api",
+	} {
+		if actual, _ := SafeRedirectURL(req, target, nil); actual != expected {
+			t.Errorf("%s: expected the redirect to %s, got %s", target, expected, actual)
+		}
+	}
+	for location, expected := range map[string]string{
+		"orders/1":      "/api/orders/1",
+		"/api/orders/1": "/api/orders/1",
+	} {
+		recorder := httptest.NewRecorder()
+		NewResponse(recorder).SetContentLocation(req, location)
+		if actual := recorder.Header().Get("Content-Location"); actual != expected {
+			t.Errorf("%s: expected the Content-Location %s, got %s", location, expected, actual)
+		}
+	}
+	recorder := httptest.NewRecorder()
+	NewResponse(recorder).CanonicalHostRedirect(req, "www.example.com")
+	if actual := recorder.Header().Get("Location"); actual != "http://www.example.com/api/path?q=1" {
+		t.Errorf("Unexpected canonical redirect %s", actual)
+	}
+}
@@ -0,0 +1,23 @@
+package revel
+
+import (
+	"net/http"
+)
+
+// The canonical keys of the headers read while resolving each request, so that
+// they may be looked up without canonicalizing them again (see headerValue).
+const (
+	headerAccept         = "Accept"
+	headerAcceptEncoding = "Accept-Encoding"
+	headerAcceptLanguage = "Accept-Language"
+	headerContentType    = "Content-Type"
+)
+
+// Return the first value of the header, like http.Header.Get, but for a key
+// that is already canonical.
+func headerValue(header http.Header, canonicalKey string) string {
+	if values := header[canonicalKey]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
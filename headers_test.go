@@ -0,0 +1,50 @@
+package revel
+
+import (
+	"net/http"
+	"testing"
+)
+
+func benchmarkHeader() http.Header {
+	return http.Header{
+		"Accept":          {"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"},
+		"Accept-Encoding": {"gzip, deflate, br"},
+		"Accept-Language": {"en-GB,en;q=0.8"},
+		"Content-Type":    {"application/x-www-form-urlencoded"},
+	}
+}
+
+func TestHeaderValue(t *testing.T) {
+	header := benchmarkHeader()
+	for _, key := range []string{headerAccept, headerAcceptEncoding, headerAcceptLanguage, headerContentType} {
+		if key != http.CanonicalHeaderKey(key) {
+			t.Errorf("%s is not canonical", key)
+		}
+		if headerValue(header, key) != header.Get(key) {
+			t.Errorf("%s: expected %q, got %q", key, header.Get(key), headerValue(header, key))
+		}
+	}
+	if headerValue(header, "Missing") != "" {
+		t.Errorf("Expected no value for a missing header")
+	}
+}
+
+func BenchmarkHeaderGet(b *testing.B) {
+	header := benchmarkHeader()
+	for i := 0; i < b.N; i++ {
+		header.Get("Accept")
+		header.Get("Accept-Encoding")
+		header.Get("Accept-Language")
+		header.Get("Content-Type")
+	}
+}
+
+func BenchmarkHeaderValue(b *testing.B) {
+	header := benchmarkHeader()
+	for i := 0; i < b.N; i++ {
+		headerValue(header, headerAccept)
+		headerValue(header, headerAcceptEncoding)
+		headerValue(header, headerAcceptLanguage)
+		headerValue(header, headerContentType)
+	}
+}
@@ -0,0 +1,55 @@
+package revel
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// Take over the connection from the HTTP server, e.g. to speak another
+// protocol over it.  It is an error to hijack once the response has been
+// started, or twice, and http.ErrNotSupported is returned if the writer can
+// not be hijacked (e.g. on HTTP/2).
+//
+// Once hijacked, the caller is responsible for the connection.  The Response
+// may no longer be written: writes to Out fail with http.ErrHijacked, and the
+// helpers that start a response report ErrHeaderWritten.
+func (resp *Response) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if resp.hijacked {
+		return nil, nil, http.ErrHijacked
+	}
+	if resp.wroteHeader {
+		return nil, nil, ErrHeaderWritten
+	}
+	hijacker, ok := resp.Out.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	resp.hijacked, resp.wroteHeader = true, true
+	resp.Out = hijackedWriter{make(http.Header)}
+	return conn, rw, nil
+}
+
+// Return true if the connection has been taken over with Hijack.
+func (resp *Response) Hijacked() bool {
+	return resp.hijacked
+}
+
+// The ResponseWriter of a hijacked response, which may no longer be written.
+type hijackedWriter struct {
+	header http.Header
+}
+
+func (w hijackedWriter) Header() http.Header { return w.header }
+
+func (w hijackedWriter) WriteHeader(status int) {
+	WARN.Println("Ignoring a response status written after the connection was hijacked")
+}
+
+func (w hijackedWriter) Write(b []byte) (int, error) {
+	return 0, http.ErrHijacked
+}
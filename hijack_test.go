@@ -0,0 +1,54 @@
+package revel
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type hijackableRecorder struct {
+	*httptest.ResponseRecorder
+	conn net.Conn
+}
+
+func (r *hijackableRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return r.conn, bufio.NewReadWriter(bufio.NewReader(r.conn), bufio.NewWriter(r.conn)), nil
+}
+
+func TestHijack(t *testing.T) {
+	conn, other := net.Pipe()
+	defer conn.Close()
+	defer other.Close()
+	recorder := &hijackableRecorder{httptest.NewRecorder(), conn}
+	resp := NewResponse(recorder)
+
+	hijacked, _, err := resp.Hijack()
+	if err != nil || hijacked != conn || !resp.Hijacked() {
+		t.Fatalf("Expected the connection, got %v (%v)", hijacked, err)
+	}
+	if _, _, err := resp.Hijack(); err != http.ErrHijacked {
+		t.Errorf("Expected ErrHijacked for the second Hijack, got %v", err)
+	}
+	if _, err := resp.Out.Write([]byte("late")); err != http.ErrHijacked {
+		t.Errorf("Expected writes to fail with ErrHijacked, got %v", err)
+	}
+	resp.WriteHeader(http.StatusOK, "text/plain")
+	if _, err := resp.SSE(newTestRequest("html")); err != ErrHeaderWritten {
+		t.Errorf("Expected ErrHeaderWritten, got %v", err)
+	}
+	if recorder.Body.Len() != 0 || recorder.Header().Get("Content-Type") != "" {
+		t.Errorf("Expected nothing to be written after the hijack, got %q", recorder.Body.String())
+	}
+
+	resp = NewResponse(httptest.NewRecorder())
+	if _, _, err := resp.Hijack(); err != http.ErrNotSupported {
+		t.Errorf("Expected ErrNotSupported, got %v", err)
+	}
+	resp = NewResponse(&hijackableRecorder{httptest.NewRecorder(), conn})
+	resp.WriteHeader(http.StatusOK, "text/plain")
+	if _, _, err := resp.Hijack(); err != ErrHeaderWritten {
+		t.Errorf("Expected ErrHeaderWritten, got %v", err)
+	}
+}
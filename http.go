@@ -2,8 +2,11 @@ package revel
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/url"
 	"sort"
 	"strconv"
 	"strings"
@@ -15,6 +18,12 @@ type Request struct {
 	Format          string // "html", "xml", "json", or "text"
 	AcceptLanguages AcceptLanguages
 	Locale          string
+
+	body             []byte                 // The body, once read by BodyBytes.
+	negotiationTrace *NegotiationTrace      // Only recorded if NegotiationDebug is on.
+	acceptSummary    *AcceptSummary         // Computed by Accept, on first use.
+	values           map[string]interface{} // Stored by Set.
+	routeParams      map[string]string      // The path parameters of the matched route.
 }
 
 type Response struct {
@@ -22,53 +31,588 @@ type Response struct {
 	ContentType string
 
 	Out http.ResponseWriter
+
+	wroteHeader bool // true once WriteHeader has sent the status line
+	hijacked    bool // true once the connection has been taken over by Hijack.
+
+	statusSent   int   // The status sent to the client, once it has been.
+	bytesWritten int64 // Body bytes written, before compression.
+	bytesSent    int64 // Body bytes sent to the client.
+	compressing  bool  // true if Out compresses (and so counts bytesWritten).
+
+	original http.ResponseWriter // The writer beneath any wrappers.
 }
 
 func NewResponse(w http.ResponseWriter) *Response {
-	return &Response{Out: w}
+	resp := &Response{original: w}
+	resp.Out = newCountingWriter(w, resp)
+	return resp
 }
 
 func NewRequest(r *http.Request) *Request {
-	return &Request{
+	req := &Request{
 		Request:         r,
 		ContentType:     ResolveContentType(r),
-		Format:          ResolveFormat(r),
 		AcceptLanguages: ResolveAcceptLanguage(r),
 	}
+	if NegotiationDebug {
+		req.negotiationTrace = &NegotiationTrace{}
+	}
+	req.Format = resolveFormat(r, req.negotiationTrace)
+	return req
+}
+
+// Return a copy of the request with the given context, as http.Request.Clone.
+// The resolved fields are copied, so that changes to the clone (including to
+// its AcceptLanguages) do not affect the original.  If the body was already
+// read with BodyBytes, the clone may read it independently.  Values stored with
+// Set are not copied.
+func (r *Request) Clone(ctx context.Context) *Request {
+	clone := *r
+	clone.Request = r.Request.Clone(ctx)
+	clone.AcceptLanguages = append(AcceptLanguages(nil), r.AcceptLanguages...)
+	if r.body != nil {
+		clone.Body = ioutil.NopCloser(bytes.NewReader(r.body))
+	}
+	clone.acceptSummary = nil
+	clone.values = nil
+	if r.negotiationTrace != nil {
+		trace := *r.negotiationTrace
+		trace.Candidates = append([]NegotiationCandidate(nil), trace.Candidates...)
+		clone.negotiationTrace = &trace
+	}
+	return &clone
+}
+
+// Store a value on the request, for later handlers and interceptors.
+// e.g. the authenticated user, or a request ID
+// Values live as long as the request, and are not shared with its clones.
+func (r *Request) Set(key string, value interface{}) {
+	if r.values == nil {
+		r.values = make(map[string]interface{})
+	}
+	r.values[key] = value
+}
+
+// Return the value stored on the request with Set, if any.
+func (r *Request) Get(key string) (value interface{}, ok bool) {
+	value, ok = r.values[key]
+	return value, ok
+}
+
+// A summary of what the client accepts, for templates to branch on.
+// e.g. {{if .request.Accept.WantsJSON}}
+type AcceptSummary struct {
+	Format          string // The request format.
+	WantsHTML       bool
+	WantsJSON       bool
+	PrimaryLanguage string // The request Locale, or else the preferred accept language.
+	AcceptsGzip     bool
+}
+
+// Return a summary of what the client accepts, derived from the resolved
+// request fields.  It is computed once, on first use, so it does not reflect
+// later changes to the request (e.g. to its Locale).
+func (r *Request) Accept() AcceptSummary {
+	if r.acceptSummary == nil {
+		summary := AcceptSummary{
+			Format:          r.Format,
+			WantsHTML:       r.Format == "html",
+			WantsJSON:       r.Format == "json",
+			PrimaryLanguage: r.Locale,
+			AcceptsGzip:     acceptEncodingQuality(r.Header[headerAcceptEncoding], "gzip") > 0,
+		}
+		if summary.PrimaryLanguage == "" && len(r.AcceptLanguages) > 0 {
+			summary.PrimaryLanguage = strings.TrimSpace(r.AcceptLanguages[0].Language)
+		}
+		r.acceptSummary = &summary
+	}
+	return *r.acceptSummary
+}
+
+// Parse the Origin header into a URL holding only the scheme and host.
+// Default ports (:80 for http, :443 for https) are dropped, and the scheme and
+// host are lower-cased, so that the result may be compared directly.
+// Returns false if the header is missing, malformed, or the literal "null".
+func (r *Request) Origin() (*url.URL, bool) {
+	origin := strings.TrimSpace(r.Header.Get("Origin"))
+	if origin == "" || origin == "null" {
+		return nil, false
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, false
+	}
+
+	scheme, host := strings.ToLower(u.Scheme), strings.ToLower(u.Host)
+	switch {
+	case scheme == "http" && strings.HasSuffix(host, ":80"):
+		host = host[:len(host)-len(":80")]
+	case scheme == "https" && strings.HasSuffix(host, ":443"):
+		host = host[:len(host)-len(":443")]
+	}
+	return &url.URL{Scheme: scheme, Host: host}, true
+}
+
+// Return true if the client asks not to be tracked (DNT: 1).
+func (r *Request) DoNotTrack() bool {
+	return strings.TrimSpace(r.Header.Get("DNT")) == "1"
+}
+
+// Return true if the client sends the Global Privacy Control signal (Sec-GPC: 1).
+func (r *Request) GlobalPrivacyControl() bool {
+	return strings.TrimSpace(r.Header.Get("Sec-GPC")) == "1"
+}
+
+// Quote a value as a header quoted-string, escaping quotes and backslashes.
+func quoteHeaderString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// Return the elements of a comma-separated list header (from all of its
+// fields), trimmed of whitespace.  Commas within quoted strings do not separate
+// elements, and quoted strings are left as they are, quotes and all.
+// e.g. `a, "b, c"` => [`a`, `"b, c"`]
+func (r *Request) HeaderList(name string) []string {
+	return splitHeaderList(r.Header[http.CanonicalHeaderKey(name)])
+}
+
+// Split the values of a comma-separated list header into its elements,
+// trimming whitespace and dropping empty elements.  Commas within quoted
+// strings (which may contain backslash-escaped quotes) do not separate
+// elements.
+func splitHeaderList(values []string) []string {
+	var elements []string
+	for _, value := range values {
+		var (
+			start  = 0
+			quoted = false
+		)
+		for i := 0; i <= len(value); i++ {
+			if i < len(value) {
+				switch {
+				case quoted && value[i] == '\\':
+					if i+1 < len(value) {
+						i++ // Skip the escaped character.
+					}
+					continue
+				case value[i] == '"':
+					quoted = !quoted
+				}
+				if quoted || value[i] != ',' {
+					continue
+				}
+			}
+			if element := strings.TrimSpace(value[start:i]); element != "" {
+				elements = append(elements, element)
+			}
+			start = i + 1
+		}
+	}
+	return elements
 }
 
 // Get the content type.
 // e.g. From "multipart/form-data; boundary=--" to "multipart/form-data"
 // If none is specified, returns "text/html" by default.
 func ResolveContentType(req *http.Request) string {
-	contentType := req.Header.Get("Content-Type")
+	contentType := headerValue(req.Header, headerContentType)
 	if contentType == "" {
 		return "text/html"
 	}
 	return strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
 }
 
+// Return true if the request body has the given media type (compared without
+// case or parameters), or, given a format name, if the request format is that
+// format or the body has one of its media types.
+// e.g. req.Is("json") and req.Is("application/json") for a JSON body
+func (r *Request) Is(contentTypeOrFormat string) bool {
+	if strings.Contains(contentTypeOrFormat, "/") {
+		mediaType := strings.TrimSpace(strings.Split(contentTypeOrFormat, ";")[0])
+		return strings.EqualFold(r.ContentType, mediaType)
+	}
+	name := strings.ToLower(contentTypeOrFormat)
+	if r.Format == name {
+		return true
+	}
+	if format := LookupFormat(name); format != nil {
+		for _, mediaType := range format.MediaTypes {
+			if strings.EqualFold(r.ContentType, mediaType) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Return an error unless the request body has one of the allowed media types
+// (compared without case or parameters).  A request without a Content-Type is
+// not allowed.  The error is an *UnsupportedMediaTypeError listing the allowed
+// types, which Response.WriteError renders as a 415.
+func (r *Request) RequireContentType(allowed ...string) error {
+	contentType := ""
+	if headerValue(r.Header, headerContentType) != "" {
+		contentType = r.ContentType
+		for _, mediaType := range allowed {
+			if strings.EqualFold(contentType, strings.TrimSpace(strings.Split(mediaType, ";")[0])) {
+				return nil
+			}
+		}
+	}
+	return &UnsupportedMediaTypeError{contentType, allowed}
+}
+
 // Resolve the accept request header.
+// A registered format named by the FormatParam query parameter takes priority.
+// Otherwise, the registered format whose media type the client finds most
+// acceptable is chosen.  Ties (e.g. "*/*") are decided by FormatPreference and
+// then the order of registration, and unacceptable requests fall back to the
+// most preferred format, or "html".
 func ResolveFormat(req *http.Request) string {
-	accept := req.Header.Get("accept")
+	return resolveFormat(req, nil)
+}
 
-	switch {
-	case accept == "",
-		strings.HasPrefix(accept, "*/*"), // */
-		strings.Contains(accept, "application/xhtml"),
-		strings.Contains(accept, "text/html"):
-		return "html"
-	case strings.Contains(accept, "application/xml"),
-		strings.Contains(accept, "text/xml"):
-		return "xml"
-	case strings.Contains(accept, "text/plain"):
-		return "txt"
-	case strings.Contains(accept, "application/json"),
-		strings.Contains(accept, "text/javascript"):
-		return "json"
+// Resolve the request format like ResolveFormat, but without the fallback:
+// ok is false if the client finds none of the registered formats acceptable,
+// so that the request may be answered with Response.NotAcceptable.  With
+// RequireAccept, ok is also false if the request has no Accept header.
+func ResolveFormatStrict(req *http.Request) (format string, ok bool) {
+	if format, ok := formatFromParam(req); ok {
+		return format, true
+	}
+	accept := ResolveAccept(req)
+	if accept == nil && RequireAccept {
+		return "", false
+	}
+	for _, f := range formats {
+		for _, mediaType := range f.MediaTypes {
+			if accept == nil || accept.Quality(mediaType) > 0 {
+				return resolveFormat(req, nil), true
+			}
+		}
+	}
+	return "", false
+}
+
+// Resolve the request format, recording the decision in the trace if non-nil.
+func resolveFormat(req *http.Request, trace *NegotiationTrace) string {
+	if format, ok := formatFromParam(req); ok {
+		if trace != nil {
+			trace.Accept, trace.Format = headerValue(req.Header, headerAccept), format
+			trace.Rule = "format query parameter"
+		}
+		return format
+	}
+
+	accept := ResolveAccept(req)
+	format, bestQuality, bestPrecedence := fallbackFormat(), float32(0), -1
+	for _, f := range preferredFormats() {
+		for _, mediaType := range f.MediaTypes {
+			quality, precedence, ok := accept.match(mediaType)
+			if trace != nil {
+				trace.Candidates = append(trace.Candidates,
+					NegotiationCandidate{f.Name, mediaType, quality, precedence})
+			}
+			if !ok || quality == 0 {
+				continue
+			}
+			if quality > bestQuality || (quality == bestQuality && precedence > bestPrecedence) {
+				format, bestQuality, bestPrecedence = f.Name, quality, precedence
+			}
+		}
+	}
+
+	if trace != nil {
+		trace.Accept = headerValue(req.Header, headerAccept)
+		trace.Format = format
+		trace.Rule = "highest quality"
+		switch {
+		case accept == nil:
+			trace.Rule = "no Accept header: using the first registered format"
+		case bestQuality == 0:
+			trace.Rule = "no registered format is acceptable: using the default"
+		default:
+			for _, candidate := range trace.Candidates {
+				if candidate.Format == format || candidate.Quality != bestQuality {
+					continue
+				}
+				if candidate.Precedence == bestPrecedence {
+					trace.Rule = "equal quality and precedence: using the first registered format"
+					break
+				}
+				trace.Rule = "equal quality: using the most specific media range"
+			}
+		}
+	}
+	return format
+}
+
+// A single media range from the Accept HTTP header.
+type AcceptMediaType struct {
+	Type, Subtype string            // e.g. "text" and "html".  Either may be "*".
+	Params        map[string]string // Media type parameters, excluding the quality.
+	Quality       float32
+}
+
+func (mt AcceptMediaType) String() string {
+	output := bytes.NewBufferString(mt.Type + "/" + mt.Subtype)
+	keys := make([]string, 0, len(mt.Params))
+	for key := range mt.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		output.WriteString(";" + key + "=" + mt.Params[key])
+	}
+	return output.String()
+}
+
+// Return how specific the media range is, following RFC 7231 section 5.3.2:
+// "*/*" is less specific than "text/*", which is less specific than
+// "text/html", which is less specific than "text/html;level=1".
+func (mt AcceptMediaType) precedence() int {
+	precedence := 0
+	if mt.Type != "*" {
+		precedence++
+		if mt.Subtype != "*" {
+			precedence++
+		}
+	}
+	return precedence*100 + len(mt.Params)
+}
+
+// Return true if the given (concrete) media type falls within this range.
+func (mt AcceptMediaType) matches(mediaType AcceptMediaType) bool {
+	if mt.Type != "*" && mt.Type != mediaType.Type {
+		return false
+	}
+	if mt.Subtype != "*" && mt.Subtype != mediaType.Subtype {
+		return false
+	}
+	for key, value := range mt.Params {
+		if mediaType.Params[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// A collection of sortable AcceptMediaType instances.
+// They sort by quality, and then by precedence (most specific first).
+type AcceptMediaTypes []AcceptMediaType
+
+func (a AcceptMediaTypes) Len() int      { return len(a) }
+func (a AcceptMediaTypes) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a AcceptMediaTypes) Less(i, j int) bool {
+	if a[i].Quality != a[j].Quality {
+		return a[i].Quality > a[j].Quality
+	}
+	return a[i].precedence() > a[j].precedence()
+}
+func (a AcceptMediaTypes) String() string {
+	output := bytes.NewBufferString("")
+	for i, mediaType := range a {
+		output.WriteString(fmt.Sprintf("%s (%1.1f)", mediaType, mediaType.Quality))
+		if i != len(a)-1 {
+			output.WriteString(", ")
+		}
+	}
+	return output.String()
+}
+
+// Return the quality the client assigns to the given media type: that of the
+// most specific media range that matches it, or 0 if none do.
+// If the client sent no Accept header, every media type has quality 1.
+func (a AcceptMediaTypes) Quality(offered string) float32 {
+	quality, _, _ := a.match(offered)
+	return quality
+}
+
+// Return the offered media type that the client finds most acceptable, or ""
+// if none are acceptable.  Offers with equal quality are decided by the
+// precedence of the media range that matched them, and then by their order.
+func (a AcceptMediaTypes) Negotiate(offered ...string) string {
+	best, bestQuality, bestPrecedence := "", float32(0), -1
+	for _, offer := range offered {
+		quality, precedence, ok := a.match(offer)
+		if !ok || quality == 0 {
+			continue
+		}
+		if quality > bestQuality || (quality == bestQuality && precedence > bestPrecedence) {
+			best, bestQuality, bestPrecedence = offer, quality, precedence
+		}
+	}
+	return best
+}
+
+// Return the quality of each offered media type (see Quality), keyed by the
+// offer as given.  Unacceptable offers are included, with quality 0.
+func (a AcceptMediaTypes) ScoreOffers(offered []string) map[string]float32 {
+	scores := make(map[string]float32, len(offered))
+	for _, offer := range offered {
+		scores[offer] = a.Quality(offer)
+	}
+	return scores
+}
+
+// Find the most specific media range matching the offered media type.
+// Returns its quality and precedence, and false if no range matched.
+func (a AcceptMediaTypes) match(offered string) (quality float32, precedence int, ok bool) {
+	if len(a) == 0 {
+		return 1, -1, true
+	}
+	mediaType, valid := parseMediaRange(offered)
+	if !valid {
+		return 0, -1, false
+	}
+	precedence = -1
+	for _, mediaRange := range a {
+		if mediaRange.precedence() > precedence && mediaRange.matches(mediaType) {
+			quality, precedence, ok = mediaRange.Quality, mediaRange.precedence(), true
+		}
+	}
+	return
+}
+
+// Resolve the Accept header value.
+//
+// The results are sorted by quality and then by precedence, so that the media
+// range the client most prefers is the first element.  Returns nil if the
+// header is missing (meaning that every media type is acceptable).
+//
+// See RFC 7231 section 5.3.2 for details.
+func ResolveAccept(req *http.Request) AcceptMediaTypes {
+	header := headerValue(req.Header, headerAccept)
+	if header == "" {
+		return nil
+	}
+
+	var acceptMediaTypes AcceptMediaTypes
+	for _, mediaRange := range strings.Split(header, ",") {
+		if mediaType, ok := parseMediaRange(mediaRange); ok {
+			acceptMediaTypes = append(acceptMediaTypes, mediaType)
+		} else if strings.TrimSpace(mediaRange) != "" {
+			WARN.Printf("Ignoring malformed media range '%s' in Accept header", mediaRange)
+		}
 	}
 
-	return "html"
+	sort.Stable(acceptMediaTypes)
+	return acceptMediaTypes
+}
+
+// Parse a media range (e.g. "text/html;level=1;q=0.5").
+// Type, subtype, and parameter names are case-insensitive, so they are lowered.
+func parseMediaRange(mediaRange string) (AcceptMediaType, bool) {
+	parts := strings.Split(mediaRange, ";")
+	typeAndSubtype := strings.Split(strings.ToLower(strings.TrimSpace(parts[0])), "/")
+	if len(typeAndSubtype) != 2 || typeAndSubtype[0] == "" || typeAndSubtype[1] == "" ||
+		(typeAndSubtype[0] == "*" && typeAndSubtype[1] != "*") {
+		return AcceptMediaType{}, false
+	}
+
+	mediaType := AcceptMediaType{
+		Type:    typeAndSubtype[0],
+		Subtype: typeAndSubtype[1],
+		Quality: 1,
+	}
+	for _, param := range parts[1:] {
+		keyValue := strings.SplitN(param, "=", 2)
+		if len(keyValue) != 2 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(keyValue[0]))
+		value := strings.Trim(strings.TrimSpace(keyValue[1]), `"`)
+		if key == "q" {
+			// Parameters following the quality are accept-extensions, not media
+			// type parameters.
+			if quality, ok := parseQuality(value); ok {
+				mediaType.Quality = quality
+			} else {
+				WARN.Printf("Detected malformed Accept header quality in '%s', assuming quality is 1", mediaRange)
+			}
+			break
+		}
+		if mediaType.Params == nil {
+			mediaType.Params = make(map[string]string)
+		}
+		mediaType.Params[key] = value
+	}
+	return mediaType, true
+}
+
+// If true, quality values that do not follow the qvalue grammar are accepted
+// if they are still numbers between 0 and 1 (e.g. ".8", "0.12345" or "5e-1"),
+// to interoperate with broken clients.  Values that are not numbers at all
+// (e.g. "high") are rejected either way.  Configured with http.quality.lenient
+// in app.conf.
+var LenientQuality = false
+
+func init() {
+	OnAppStart(func() {
+		LenientQuality = Config.BoolDefault("http.quality.lenient", false)
+	})
+}
+
+// Parse a quality value (the "q" parameter), which must be between 0 and 1.
+//
+// Strictly, the value must be a qvalue (RFC 7231 section 5.3.1): "0" or "1",
+// optionally followed by a "." and up to three digits (only zeros after a "1").
+// See LenientQuality for the alternative.
+func parseQuality(value string) (float32, bool) {
+	value = strings.TrimSpace(value)
+	if !LenientQuality && !isQValue(value) {
+		return 0, false
+	}
+	quality, err := strconv.ParseFloat(value, 32)
+	if err != nil || quality < 0 || quality > 1 {
+		return 0, false
+	}
+	return float32(quality), true
+}
+
+// Return true if the value matches the qvalue grammar:
+//
+//     qvalue = ( "0" [ "." 0*3DIGIT ] ) / ( "1" [ "." 0*3("0") ] )
+func isQValue(value string) bool {
+	if value == "" || (value[0] != '0' && value[0] != '1') {
+		return false
+	}
+	if len(value) == 1 {
+		return true
+	}
+	if value[1] != '.' || len(value) > 5 {
+		return false
+	}
+	for _, c := range value[2:] {
+		if c < '0' || c > '9' || (value[0] == '1' && c != '0') {
+			return false
+		}
+	}
+	return true
+}
+
+// The languages assumed for requests without an Accept-Language header, so
+// that locale matching always has something to work with.  Empty by default.
+// Configured with i18n.default_accept_language in app.conf, written like the
+// header.  (e.g. "en-US, en;q=0.5")
+var DefaultAcceptLanguages AcceptLanguages
+
+func init() {
+	OnAppStart(func() {
+		if header := Config.StringDefault("i18n.default_accept_language", ""); header != "" {
+			DefaultAcceptLanguages = ResolveAcceptLanguage(&http.Request{
+				Header: http.Header{"Accept-Language": {header}},
+			})
+		}
+	})
+}
+
+// Return true if the client sent an Accept-Language header, i.e. the request
+// AcceptLanguages are not the DefaultAcceptLanguages.
+func (r *Request) SentAcceptLanguage() bool {
+	return headerValue(r.Header, headerAcceptLanguage) != ""
 }
 
 // A single language from the Accept-Language HTTP header.
@@ -101,10 +645,16 @@ func (al AcceptLanguages) String() string {
 //
 // See the HTTP header fields specification 
 // (http://www.w3.org/Protocols/rfc2616/rfc2616-sec14.html#sec14.4) for more details.
+//
+// If the header is absent, a copy of DefaultAcceptLanguages is returned instead
+// (see Request.SentAcceptLanguage to tell the two apart).
 func ResolveAcceptLanguage(req *http.Request) AcceptLanguages {
-	header := req.Header.Get("Accept-Language")
+	header := headerValue(req.Header, headerAcceptLanguage)
 	if header == "" {
-		return nil
+		if DefaultAcceptLanguages == nil {
+			return nil
+		}
+		return append(AcceptLanguages(nil), DefaultAcceptLanguages...)
 	}
 
 	acceptLanguageHeaderValues := strings.Split(header, ",")
@@ -112,12 +662,12 @@ func ResolveAcceptLanguage(req *http.Request) AcceptLanguages {
 
 	for i, languageRange := range acceptLanguageHeaderValues {
 		if qualifiedRange := strings.Split(languageRange, ";q="); len(qualifiedRange) == 2 {
-			quality, error := strconv.ParseFloat(qualifiedRange[1], 32)
-			if error != nil {
+			quality, ok := parseQuality(qualifiedRange[1])
+			if !ok {
 				WARN.Printf("Detected malformed Accept-Language header quality in '%s', assuming quality is 1", languageRange)
 				acceptLanguages[i] = AcceptLanguage{qualifiedRange[0], 1}
 			} else {
-				acceptLanguages[i] = AcceptLanguage{qualifiedRange[0], float32(quality)}
+				acceptLanguages[i] = AcceptLanguage{qualifiedRange[0], quality}
 			}
 		} else {
 			acceptLanguages[i] = AcceptLanguage{languageRange, 1}
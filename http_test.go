@@ -0,0 +1,359 @@
+package revel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOrigin(t *testing.T) {
+	testCases := map[string]string{
+		"https://example.com":     "https://example.com",
+		"https://example.com:443": "https://example.com",
+		"http://Example.COM:80":   "http://example.com",
+		"http://example.com:8080": "http://example.com:8080",
+		"https://example.com:80":  "https://example.com:80",
+		"null":                    "",
+		"":                        "",
+		"example.com":             "",
+	}
+	for header, expected := range testCases {
+		httpRequest, _ := http.NewRequest("GET", "/", nil)
+		if header != "" {
+			httpRequest.Header.Set("Origin", header)
+		}
+		origin, ok := NewRequest(httpRequest).Origin()
+		if expected == "" {
+			if ok {
+				t.Errorf("%q: expected no origin, got %s", header, origin)
+			}
+			continue
+		}
+		if !ok || origin.String() != expected {
+			t.Errorf("%q: expected %s, got %v (ok=%v)", header, expected, origin, ok)
+		}
+	}
+}
+
+func TestPrivacySignals(t *testing.T) {
+	testCases := map[string]bool{
+		"":     false, // Not sent.
+		"1":    true,
+		" 1 ":  true,
+		"0":    false,
+		"yes":  false,
+		"true": false,
+		"11":   false,
+		"1, 1": false,
+		"null": false,
+		"\t":   false,
+	}
+	for header, expected := range testCases {
+		for _, name := range []string{"DNT", "Sec-GPC"} {
+			httpRequest, _ := http.NewRequest("GET", "/", nil)
+			if header != "" {
+				httpRequest.Header.Set(name, header)
+			}
+			req := NewRequest(httpRequest)
+			actual := req.DoNotTrack()
+			if name == "Sec-GPC" {
+				actual = req.GlobalPrivacyControl()
+			}
+			if actual != expected {
+				t.Errorf("%s: %q: expected %v, got %v", name, header, expected, actual)
+			}
+		}
+	}
+}
+
+func acceptRequest(accept string) *http.Request {
+	httpRequest, _ := http.NewRequest("GET", "/", nil)
+	if accept != "" {
+		httpRequest.Header.Set("Accept", accept)
+	}
+	return httpRequest
+}
+
+// The example from RFC 7231 section 5.3.2.
+func TestAcceptQuality(t *testing.T) {
+	accept := ResolveAccept(acceptRequest(
+		"text/*;q=0.3, text/html;q=0.7, text/html;level=1, text/html;level=2;q=0.4, */*;q=0.5"))
+	testCases := map[string]float32{
+		"text/html;level=1": 1,
+		"text/html":         0.7,
+		"text/plain":        0.3,
+		"image/jpeg":        0.5,
+		"text/html;level=2": 0.4,
+		"text/html;level=3": 0.7,
+	}
+	for mediaType, expected := range testCases {
+		if actual := accept.Quality(mediaType); actual != expected {
+			t.Errorf("%s: expected quality %v, got %v", mediaType, expected, actual)
+		}
+	}
+
+	expectedOrder := "text/html;level=1 (1.0), text/html (0.7), */* (0.5), text/html;level=2 (0.4), text/* (0.3)"
+	if accept.String() != expectedOrder {
+		t.Errorf("Expected %s, got %s", expectedOrder, accept)
+	}
+}
+
+func TestAcceptPrecedence(t *testing.T) {
+	// More specific ranges win at equal quality.
+	accept := ResolveAccept(acceptRequest("*/*, text/*, text/plain, text/plain;format=flowed"))
+	expectedOrder := "text/plain;format=flowed (1.0), text/plain (1.0), text/* (1.0), */* (1.0)"
+	if accept.String() != expectedOrder {
+		t.Errorf("Expected %s, got %s", expectedOrder, accept)
+	}
+
+	testCases := []struct {
+		accept   string
+		offered  []string
+		expected string
+	}{
+		{"text/*, text/html", []string{"text/plain", "text/html"}, "text/html"},
+		{"*/*, application/json", []string{"text/html", "application/json"}, "application/json"},
+		{"text/html;q=0.5, application/json", []string{"text/html", "application/json"}, "application/json"},
+		{"text/*;q=0.5, */*;q=0.1", []string{"image/png", "text/csv"}, "text/csv"},
+		{"text/html;q=0", []string{"text/html"}, ""},
+		{"", []string{"text/csv", "text/html"}, "text/csv"},
+	}
+	for _, testCase := range testCases {
+		actual := ResolveAccept(acceptRequest(testCase.accept)).Negotiate(testCase.offered...)
+		if actual != testCase.expected {
+			t.Errorf("%q offering %v: expected %q, got %q", testCase.accept, testCase.offered, testCase.expected, actual)
+		}
+	}
+}
+
+func TestScoreOffers(t *testing.T) {
+	accept := ResolveAccept(acceptRequest("text/html, application/json;q=0.8, */*;q=0.1"))
+	expected := map[string]float32{"text/html": 1, "application/json": 0.8, "image/png": 0.1, "bogus": 0}
+	if actual := accept.ScoreOffers([]string{"text/html", "application/json", "image/png", "bogus"}); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestResolveFormat(t *testing.T) {
+	testCases := map[string]string{
+		"":    "html",
+		"*/*": "html",
+		"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8": "html",
+		"application/json":                  "json",
+		"application/json, */*":             "json",
+		"text/*, text/plain":                "txt",
+		"text/html;q=0.5, application/json": "json",
+		"application/xml;q=0.9, text/xml":   "xml",
+		"image/png":                         "html",
+	}
+	for accept, expected := range testCases {
+		if actual := ResolveFormat(acceptRequest(accept)); actual != expected {
+			t.Errorf("%q: expected %s, got %s", accept, expected, actual)
+		}
+	}
+}
+
+func TestResolveFormatPreference(t *testing.T) {
+	defer func() { FormatPreference = nil }()
+	FormatPreference = []string{"json", "bogus", "html"}
+
+	testCases := map[string]string{
+		"":    "json",
+		"*/*": "json",
+		"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8": "html",
+		"text/html, */*;q=0.1": "html",
+		"application/xml":      "xml",
+		"image/png":            "json",
+	}
+	for accept, expected := range testCases {
+		if actual := ResolveFormat(acceptRequest(accept)); actual != expected {
+			t.Errorf("%q: expected %s, got %s", accept, expected, actual)
+		}
+	}
+}
+
+func TestResolveFormatParam(t *testing.T) {
+	testCases := map[string]string{
+		"/?format=json": "json",
+		"/?format=XML":  "xml",
+		"/?format=pdf":  "html",
+		"/?format=":     "html",
+	}
+	for url, expected := range testCases {
+		httpRequest, _ := http.NewRequest("GET", url, nil)
+		httpRequest.Header.Set("Accept", "text/html")
+		if actual := ResolveFormat(httpRequest); actual != expected {
+			t.Errorf("%s: expected %s, got %s", url, expected, actual)
+		}
+	}
+}
+
+func TestRequestClone(t *testing.T) {
+	httpRequest, _ := http.NewRequest("POST", "/", strings.NewReader("body"))
+	httpRequest.Header.Set("Accept-Language", "en-GB,nl;q=0.5")
+	req := NewRequest(httpRequest)
+	req.Locale = "en-GB"
+	req.BodyBytes()
+
+	clone := req.Clone(context.Background())
+	clone.AcceptLanguages[0].Language = "fr"
+	clone.Header.Set("Accept-Language", "fr")
+	if req.AcceptLanguages[0].Language != "en-GB" || req.Header.Get("Accept-Language") != "en-GB,nl;q=0.5" {
+		t.Errorf("Expected the original to be unchanged, got %v", req.AcceptLanguages)
+	}
+	if clone.Locale != "en-GB" || clone.Format != req.Format || clone.ContentType != req.ContentType {
+		t.Errorf("Expected the resolved fields to be copied, got %+v", clone)
+	}
+	if body, _ := clone.BodyString(); body != "body" {
+		t.Errorf("Expected the clone to read the body, got %q", body)
+	}
+}
+
+func TestAcceptSummary(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/", nil)
+	httpRequest.Header.Set("Accept", "application/json")
+	httpRequest.Header.Set("Accept-Language", "nl;q=0.5, en-GB")
+	httpRequest.Header.Set("Accept-Encoding", "gzip, deflate")
+	req := NewRequest(httpRequest)
+
+	expected := AcceptSummary{Format: "json", WantsJSON: true, PrimaryLanguage: "en-GB", AcceptsGzip: true}
+	if actual := req.Accept(); actual != expected {
+		t.Errorf("Expected %+v, got %+v", expected, actual)
+	}
+	req.Locale = "nl"
+	if actual := req.Accept(); actual != expected {
+		t.Errorf("Expected the summary to be cached, got %+v", actual)
+	}
+}
+
+func TestRequestIs(t *testing.T) {
+	testCases := []struct {
+		contentType, accept, query string
+		expected                   bool
+	}{
+		{"application/json; charset=utf-8", "", "application/json", true},
+		{"Application/JSON", "", "application/json", true},
+		{"application/json", "", "json", true},
+		{"text/javascript", "", "JSON", true},
+		{"application/xml", "", "application/json", false},
+		{"application/xml", "application/json", "json", true},
+		{"application/xml", "", "json", false},
+		{"text/csv", "", "csv", false},
+	}
+	for _, testCase := range testCases {
+		httpRequest, _ := http.NewRequest("POST", "/", nil)
+		httpRequest.Header.Set("Content-Type", testCase.contentType)
+		if testCase.accept != "" {
+			httpRequest.Header.Set("Accept", testCase.accept)
+		}
+		if actual := NewRequest(httpRequest).Is(testCase.query); actual != testCase.expected {
+			t.Errorf("%+v: expected %v", testCase, testCase.expected)
+		}
+	}
+}
+
+func TestRequireContentType(t *testing.T) {
+	allowed := []string{"application/json", "application/xml; charset=utf-8"}
+	testCases := map[string]bool{
+		"application/json":                true,
+		"Application/JSON; charset=utf-8": true,
+		"application/xml":                 true,
+		"text/xml":                        false,
+		"application/json-patch+json":     false,
+		"":                                false,
+	}
+	for contentType, expected := range testCases {
+		httpRequest, _ := http.NewRequest("POST", "/", nil)
+		if contentType != "" {
+			httpRequest.Header.Set("Content-Type", contentType)
+		}
+		err := NewRequest(httpRequest).RequireContentType(allowed...)
+		if expected {
+			if err != nil {
+				t.Errorf("%q: expected it to be allowed, got %v", contentType, err)
+			}
+			continue
+		}
+		var mediaTypeErr *UnsupportedMediaTypeError
+		if !errors.As(err, &mediaTypeErr) || !reflect.DeepEqual(mediaTypeErr.Accepted, allowed) {
+			t.Errorf("%q: expected an UnsupportedMediaTypeError, got %v", contentType, err)
+		} else if status, _ := errorStatusCode(err); status != http.StatusUnsupportedMediaType {
+			t.Errorf("%q: expected a 415, got %d", contentType, status)
+		}
+	}
+}
+
+func TestHeaderList(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/", nil)
+	httpRequest.Header.Add("X-List", `a, "b, c" ,, d`)
+	httpRequest.Header.Add("X-List", `"e \"f, g\" h", i="j,k", "l\\", m`)
+	httpRequest.Header.Add("X-List", `"unterminated, n\`)
+
+	expected := []string{`a`, `"b, c"`, `d`, `"e \"f, g\" h"`, `i="j,k"`, `"l\\"`, `m`, `"unterminated, n\`}
+	if actual := NewRequest(httpRequest).HeaderList("x-list"); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+	if actual := NewRequest(httpRequest).HeaderList("X-Missing"); len(actual) != 0 {
+		t.Errorf("Expected no elements for a missing header, got %q", actual)
+	}
+}
+
+func TestParseQuality(t *testing.T) {
+	defer func() { LenientQuality = false }()
+	testCases := []struct {
+		value           string
+		strict, lenient bool
+	}{
+		{"0", true, true},
+		{"1", true, true},
+		{"0.8", true, true},
+		{"0.125", true, true},
+		{"1.", true, true},
+		{"1.000", true, true},
+		{" 0.5 ", true, true},
+		{".8", false, true},
+		{"0.12345", false, true},
+		{"5e-1", false, true},
+		{"1.001", false, false},
+		{"1.5", false, false},
+		{"-0.5", false, false},
+		{"high", false, false},
+		{"", false, false},
+	}
+	for _, testCase := range testCases {
+		for _, lenient := range []bool{false, true} {
+			LenientQuality = lenient
+			expected := testCase.strict
+			if lenient {
+				expected = testCase.lenient
+			}
+			if _, ok := parseQuality(testCase.value); ok != expected {
+				t.Errorf("%q (lenient=%v): expected ok=%v", testCase.value, lenient, expected)
+			}
+		}
+	}
+}
+
+func TestRequestValues(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/", nil)
+	req := NewRequest(httpRequest)
+	if _, ok := req.Get("user"); ok {
+		t.Errorf("Expected no value before it is set")
+	}
+	req.Set("user", "alice")
+	if value, ok := req.Get("user"); !ok || value != "alice" {
+		t.Errorf("Expected the stored value, got %v %v", value, ok)
+	}
+
+	clone := req.Clone(context.Background())
+	if _, ok := clone.Get("user"); ok {
+		t.Errorf("Expected the clone not to share the values")
+	}
+	clone.Set("user", "bob")
+	if value, _ := req.Get("user"); value != "alice" {
+		t.Errorf("Expected the original to be unchanged, got %v", value)
+	}
+}
@@ -3,6 +3,7 @@ package revel
 import (
 	"fmt"
 	"github.com/robfig/config"
+	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -17,11 +18,23 @@ const (
 	unknownValueFormat    = "??? %s ???"
 	defaultLanguageOption = "i18n.default_language"
 	localeCookieConfigKey = "i18n.cookie"
+
+	localeFallbackConfigPrefix = "i18n.fallback."
 )
 
 var (
 	// All currently loaded message configs.
 	messages map[string]*config.Config
+
+	// The territory defaults used when matching locales: a bare language maps
+	// to the full locale that should be used for it. (e.g. "pt" => "pt-BR")
+	// Configured in app.conf with i18n.fallback.<language> options.
+	LocaleFallbacks = make(map[string]string)
+
+	// If true (the default), the locale saved by SelectAndPersistLocale wins over
+	// the Accept-Language header.  If false, it only breaks ties with the header.
+	// Configured with i18n.forceuserchoice in app.conf.
+	LocaleForceUserChoice = true
 )
 
 // Return all currently loaded message languages.
@@ -76,7 +89,127 @@ func Message(locale, message string, args ...interface{}) string {
 	return value
 }
 
+// Return the supported locale that best matches the given accept languages.
+//
+// The languages are considered in order.  For each, a supported locale is
+// looked for (case-insensitively) as follows, stopping at the first match:
+//   1. The language itself. (e.g. "en-GB")
+//   2. Its territory default from LocaleFallbacks.
+//   3. Steps 1 and 2 for each shorter prefix of the language. (e.g. "en")
+// Before a prefix drops the script subtag (e.g. going from "zh-Hant" to "zh"),
+// any supported locale with the same language and script is preferred (e.g.
+// "zh-Hant-HK"), so that a script-less match is only made as a last resort.
+// The script may also be implied by the region. (e.g. "zh-TW" => "Hant")
+// Languages with quality 0 are not acceptable, and are skipped.  The "*"
+// range matches the first supported locale.  Extension and private use
+// subtags (e.g. "de-DE-u-co-phonebk") are ignored for matching.
+func MatchLocale(acceptLanguages AcceptLanguages, supported []string) (string, bool) {
+	for _, acceptLanguage := range acceptLanguages {
+		if acceptLanguage.Quality == 0 {
+			continue
+		}
+		language := basicLocale(strings.TrimSpace(acceptLanguage.Language))
+		if language == "*" && len(supported) > 0 {
+			return supported[0], true
+		}
+		primary, script := localeScript(language)
+		for candidate := language; candidate != ""; candidate = truncateLocale(candidate) {
+			if _, candidateScript := localeScript(candidate); script != "" && candidateScript == "" {
+				if locale, ok := findLocaleWithScript(primary, script, supported); ok {
+					return locale, true
+				}
+				script = ""
+			}
+			if locale, ok := findLocale(candidate, supported); ok {
+				return locale, true
+			}
+			if fallback, ok := LocaleFallbacks[strings.ToLower(candidate)]; ok {
+				if locale, ok := findLocale(fallback, supported); ok {
+					return locale, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// Strip the extension and private use subtags from the given BCP 47 tag, which
+// start at the first single character subtag after the language.
+// (e.g. "de-DE-u-co-phonebk" => "de-DE", "en-x-twain" => "en")
+func basicLocale(locale string) string {
+	subtags := strings.Split(locale, "-")
+	for i := 1; i < len(subtags); i++ {
+		if len(subtags[i]) == 1 {
+			return strings.Join(subtags[:i], "-")
+		}
+	}
+	return locale
+}
+
+// Drop the last subtag of the given locale. (e.g. "zh-Hant-TW" => "zh-Hant")
+func truncateLocale(locale string) string {
+	if i := strings.LastIndex(locale, "-"); i != -1 {
+		return locale[:i]
+	}
+	return ""
+}
+
+// Return the supported locale equal to the given one, ignoring case.
+func findLocale(locale string, supported []string) (string, bool) {
+	for _, supportedLocale := range supported {
+		if strings.EqualFold(locale, supportedLocale) {
+			return supportedLocale, true
+		}
+	}
+	return "", false
+}
+
+// Return the supported locale with the given language and script, ignoring case.
+func findLocaleWithScript(language, script string, supported []string) (string, bool) {
+	for _, supportedLocale := range supported {
+		supportedLanguage, supportedScript := localeScript(supportedLocale)
+		if strings.EqualFold(language, supportedLanguage) && strings.EqualFold(script, supportedScript) {
+			return supportedLocale, true
+		}
+	}
+	return "", false
+}
+
+// The scripts implied by regions that do not use the default script of their
+// language, for tags that do not specify one.  Keyed by lowercase tag.
+var impliedScripts = map[string]string{
+	"zh-tw": "Hant",
+	"zh-hk": "Hant",
+	"zh-mo": "Hant",
+	"zh-cn": "Hans",
+	"zh-sg": "Hans",
+}
+
+// Return the primary language and script subtags of the given BCP 47 tag.
+// (e.g. "zh-Hant-TW" => "zh", "Hant")  The script is empty if the tag has none
+// and none is implied by its region.
+func localeScript(locale string) (language, script string) {
+	subtags := strings.Split(locale, "-")
+	if len(subtags) > 1 && len(subtags[1]) == 4 && isAlpha(subtags[1]) {
+		return subtags[0], subtags[1]
+	}
+	if len(subtags) > 1 {
+		script = impliedScripts[strings.ToLower(subtags[0]+"-"+subtags[1])]
+	}
+	return subtags[0], script
+}
+
+func isAlpha(s string) bool {
+	for _, c := range s {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
 func parseLocale(locale string) (language, region string) {
+	locale = basicLocale(locale)
 	if strings.Contains(locale, "-") {
 		languageAndRegion := strings.Split(locale, "-")
 		return languageAndRegion[0], languageAndRegion[1]
@@ -142,6 +275,17 @@ type I18nPlugin struct {
 
 func (p I18nPlugin) OnAppStart() {
 	loadMessages(filepath.Join(BasePath, messageFilesDirectory))
+	loadLocaleFallbacks()
+	LocaleForceUserChoice = Config.BoolDefault("i18n.forceuserchoice", LocaleForceUserChoice)
+}
+
+// Read the territory defaults (e.g. i18n.fallback.pt=pt-BR) from app.conf.
+func loadLocaleFallbacks() {
+	for _, key := range Config.Options(localeFallbackConfigPrefix) {
+		if locale := Config.StringDefault(key, ""); locale != "" {
+			LocaleFallbacks[strings.ToLower(key[len(localeFallbackConfigPrefix):])] = locale
+		}
+	}
 }
 
 func (p I18nPlugin) BeforeRequest(c *Controller) {
@@ -157,6 +301,96 @@ func (p I18nPlugin) BeforeRequest(c *Controller) {
 	}
 }
 
+// The request parameter with which a user may explicitly choose a locale.
+const localeParamName = "locale"
+
+// How long the locale chosen by SelectAndPersistLocale is remembered.
+const localeCookieMaxAge = 365 * 24 * 60 * 60 // 1 year, in seconds
+
+// Select the best supported locale for the request, set it as the request
+// Locale, and remember it in a cookie for later requests.
+//
+// The locale is resolved from, in order of precedence:
+//   1. The "locale" query parameter: the user choosing a locale.
+//   2. The cookie: the choice the user made before.  With LocaleForceUserChoice
+//      (the default), it wins over the Accept-Language header, even if the
+//      header gives another supported locale a higher quality.  Otherwise, the
+//      saved choice only breaks ties: it is used if the header gives it at
+//      least the quality of the header's best match, or if no supported locale
+//      matches the header (or it was not sent).  Either way, the saved choice
+//      is kept.
+//   3. The Accept-Language header (see MatchLocale).
+// Values that are not supported are ignored, and if nothing matches, the first
+// supported locale is used.  A saved choice is only replaced by an explicit
+// one, never by a locale from the header.  The cookie name defaults to the
+// i18n.cookie setting (or <cookie.prefix>_LANG), and the cookie is only written
+// if its value changes.
+func (r *Request) SelectAndPersistLocale(resp *Response, supported []string, cookieName string) string {
+	if cookieName == "" {
+		cookieName = Config.StringDefault(localeCookieConfigKey, CookiePrefix+"_LANG")
+	}
+	var cookieValue string
+	if cookie, err := r.Cookie(cookieName); err == nil {
+		cookieValue = cookie.Value
+	}
+	_, saved := MatchLocale(AcceptLanguages{{cookieValue, 1}}, supported)
+
+	locale, ok := "", false
+	if explicit := r.URL.Query().Get(localeParamName); explicit != "" {
+		locale, ok = MatchLocale(AcceptLanguages{{explicit, 1}}, supported)
+	}
+	persist := ok || !saved
+	if !ok && cookieValue != "" {
+		if locale, ok = MatchLocale(AcceptLanguages{{cookieValue, 1}}, supported); ok && !LocaleForceUserChoice && r.SentAcceptLanguage() {
+			ok = localeQuality(r.AcceptLanguages, supported, locale) >= bestLocaleQuality(r.AcceptLanguages, supported)
+		}
+	}
+	if !ok {
+		if locale, ok = MatchLocale(r.AcceptLanguages, supported); !ok && len(supported) > 0 {
+			locale = supported[0]
+		}
+	}
+
+	r.Locale = locale
+	if persist && locale != "" && locale != cookieValue {
+		resp.SetCookie(&http.Cookie{
+			Name:     cookieName,
+			Value:    locale,
+			MaxAge:   localeCookieMaxAge,
+			HttpOnly: true,
+		})
+	}
+	return locale
+}
+
+// Return the highest quality that the accept languages give any supported
+// locale, or 0 if none is acceptable.  (The languages are sorted by quality.)
+func bestLocaleQuality(acceptLanguages AcceptLanguages, supported []string) float32 {
+	for _, acceptLanguage := range acceptLanguages {
+		if _, ok := MatchLocale(AcceptLanguages{acceptLanguage}, supported); ok {
+			return acceptLanguage.Quality
+		}
+	}
+	return 0
+}
+
+// Return the highest quality that the accept languages give the (supported)
+// locale, or 0 if it is not acceptable.
+func localeQuality(acceptLanguages AcceptLanguages, supported []string, locale string) float32 {
+	var best float32
+	for _, acceptLanguage := range acceptLanguages {
+		if acceptLanguage.Quality <= best {
+			continue
+		}
+		if strings.TrimSpace(acceptLanguage.Language) == "*" {
+			best = acceptLanguage.Quality
+		} else if match, ok := MatchLocale(AcceptLanguages{acceptLanguage}, supported); ok && match == locale {
+			best = acceptLanguage.Quality
+		}
+	}
+	return best
+}
+
 // Set the current locale controller argument (CurrentLocaleControllerArg) with the given locale.
 func setCurrentLocaleControllerArguments(c *Controller, locale string) {
 	c.Request.Locale = locale
@@ -177,7 +411,7 @@ func hasAcceptLanguageHeader(request *Request) (bool, string) {
 
 // Determine whether the given request has a valid language cookie value.
 func hasLocaleCookie(request *Request) (bool, string) {
-	if request != nil && request.Cookies() != nil {
+	if request != nil && request.Request.Cookies() != nil {
 		name := Config.StringDefault(localeCookieConfigKey, CookiePrefix+"_LANG")
 		if cookie, error := request.Cookie(name); error == nil {
 			return true, cookie.Value
@@ -4,7 +4,9 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -132,6 +134,117 @@ func TestBeforeRequest(t *testing.T) {
 	if plugin.BeforeRequest(controller); controller.Request.Locale != "en-GB" {
 		t.Errorf("Expected to find current language '%s' in controller, found '%s' instead", "en-GB", controller.Request.Locale)
 	}
+
+	controller = NewController(buildRequestWithAcceptLanguages("de-DE-u-co-phonebk"), nil, &ControllerType{reflect.TypeOf(Controller{}), nil})
+	if plugin.BeforeRequest(controller); controller.Request.Locale != "de-DE-u-co-phonebk" {
+		t.Errorf("Expected to find current language '%s' in controller, found '%s' instead", "de-DE-u-co-phonebk", controller.Request.Locale)
+	}
+}
+
+func TestMatchLocale(t *testing.T) {
+	LocaleFallbacks = map[string]string{"en": "en-US", "pt": "pt-BR"}
+	defer func() { LocaleFallbacks = make(map[string]string) }()
+
+	testCases := []struct {
+		languages []string
+		supported []string
+		expected  string
+	}{
+		{[]string{"en"}, []string{"en", "en-US"}, "en"},
+		{[]string{"en"}, []string{"nl", "en-US"}, "en-US"},
+		{[]string{"en-gb"}, []string{"en-GB", "en-US"}, "en-GB"},
+		{[]string{"en-GB"}, []string{"en", "en-US"}, "en"},
+		{[]string{"en-GB"}, []string{"en-US"}, "en-US"},
+		{[]string{"pt"}, []string{"pt-PT", "pt-BR"}, "pt-BR"},
+		{[]string{"fr", "nl"}, []string{"en", "nl"}, "nl"},
+		{[]string{"*"}, []string{"nl", "en"}, "nl"},
+		{[]string{"fr"}, []string{"en", "nl"}, ""},
+		{[]string{"zh-Hant-TW"}, []string{"zh-Hans", "zh-Hant"}, "zh-Hant"},
+		{[]string{"zh-Hant-TW"}, []string{"zh", "zh-Hant-HK"}, "zh-Hant-HK"},
+		{[]string{"zh-Hant-TW"}, []string{"zh-Hans", "zh"}, "zh"},
+		{[]string{"zh-Hans"}, []string{"zh-Hant-TW", "zh-Hans-CN"}, "zh-Hans-CN"},
+		{[]string{"zh-Hans"}, []string{"zh-Hant"}, ""},
+		{[]string{"zh-TW"}, []string{"zh-Hans", "zh-Hant"}, "zh-Hant"},
+		{[]string{"zh"}, []string{"zh-Hans", "zh"}, "zh"},
+		{[]string{"zh"}, []string{"zh-Hant"}, ""},
+		{[]string{"de-DE-u-co-phonebk"}, []string{"en", "de-DE"}, "de-DE"},
+		{[]string{"de-u-co-phonebk"}, []string{"de-DE", "de"}, "de"},
+		{[]string{"en-US-x-twain"}, []string{"en"}, "en"},
+		{[]string{"x-private"}, []string{"en"}, ""},
+	}
+	for _, testCase := range testCases {
+		request := buildRequestWithAcceptLanguages(testCase.languages...)
+		actual, ok := MatchLocale(request.AcceptLanguages, testCase.supported)
+		if actual != testCase.expected || ok != (testCase.expected != "") {
+			t.Errorf("%v with %v supported: expected %q, got %q", testCase.languages, testCase.supported, testCase.expected, actual)
+		}
+	}
+}
+
+func TestSelectAndPersistLocale(t *testing.T) {
+	defer func() { LocaleForceUserChoice = true }()
+	supported := []string{"en", "nl", "fr"}
+	testCases := []struct {
+		url, cookie, language string
+		force                 bool
+		expected              string
+		persisted             bool
+	}{
+		{"/", "", "nl", true, "nl", true},
+		{"/", "", "de", true, "en", true},
+		{"/", "fr", "nl", true, "fr", false},
+		{"/", "de", "nl", true, "nl", true},
+		{"/?locale=nl", "fr", "en", true, "nl", true},
+		{"/?locale=de", "fr", "en", true, "fr", false},
+		{"/?locale=fr", "fr", "en", true, "fr", false},
+		{"/?locale=fr", "", "en", false, "fr", true},
+
+		// The saved choice conflicts with a language the header prefers.  It is
+		// kept, even when the header wins.
+		{"/", "fr", "nl", false, "nl", false},
+		{"/", "fr", "nl,fr;q=0.5", true, "fr", false},
+		{"/", "fr", "nl,fr;q=0.5", false, "nl", false},
+		// Without forcing, the saved choice still breaks ties...
+		{"/", "fr", "nl,fr", false, "fr", false},
+		{"/", "fr", "nl;q=0.8,*;q=0.8", false, "fr", false},
+		// ...and wins when the header matches nothing, or is absent.
+		{"/", "fr", "de", false, "fr", false},
+		{"/", "fr", "", false, "fr", false},
+		{"/?locale=nl", "fr", "fr", false, "nl", true},
+	}
+	for _, testCase := range testCases {
+		httpRequest, _ := http.NewRequest("GET", testCase.url, nil)
+		if testCase.language != "" {
+			httpRequest.Header.Set("Accept-Language", testCase.language)
+		}
+		request := NewRequest(httpRequest)
+		if testCase.cookie != "" {
+			request.AddCookie(&http.Cookie{Name: "LANG", Value: testCase.cookie})
+		}
+		recorder := httptest.NewRecorder()
+		LocaleForceUserChoice = testCase.force
+		actual := request.SelectAndPersistLocale(NewResponse(recorder), supported, "LANG")
+		if actual != testCase.expected || request.Locale != testCase.expected {
+			t.Errorf("%+v: expected %q, got %q", testCase, testCase.expected, actual)
+		}
+		setCookie := recorder.Header().Get("Set-Cookie")
+		if testCase.persisted != (setCookie != "") {
+			t.Errorf("%+v: expected persisted=%v, got Set-Cookie %q", testCase, testCase.persisted, setCookie)
+		} else if testCase.persisted && !strings.HasPrefix(setCookie, "LANG="+testCase.expected+"; Path=/;") {
+			t.Errorf("%+v: unexpected Set-Cookie %q", testCase, setCookie)
+		}
+	}
+
+	// The cookie name defaults to the i18n.cookie setting.
+	loadTestI18nConfig(t)
+	name := Config.StringDefault(localeCookieConfigKey, "")
+	httpRequest, _ := http.NewRequest("GET", "/", nil)
+	request := NewRequest(httpRequest)
+	request.AddCookie(&http.Cookie{Name: name, Value: "fr"})
+	recorder := httptest.NewRecorder()
+	if actual := request.SelectAndPersistLocale(NewResponse(recorder), supported, ""); name == "" || actual != "fr" {
+		t.Errorf("Expected the locale from the %q cookie, got %q", name, actual)
+	}
 }
 
 func BenchmarkI18nLoadMessages(b *testing.B) {
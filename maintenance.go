@@ -0,0 +1,65 @@
+package revel
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	// If true, requests are answered with 503 Service Unavailable, except for
+	// those to the allowed paths or from the allowed IPs.
+	// Configured with maintenance, maintenance.paths, maintenance.ips, and
+	// maintenance.retryafter (in seconds) in app.conf.
+	MaintenanceMode       = false
+	MaintenancePaths      []string      // Path prefixes that bypass maintenance. e.g. "/health"
+	MaintenanceIPs        []string      // Client IPs that bypass maintenance.
+	MaintenanceRetryAfter time.Duration // Sent as Retry-After, if non-zero.
+)
+
+func init() {
+	OnAppStart(func() {
+		MaintenanceMode = Config.BoolDefault("maintenance", false)
+		MaintenancePaths = splitConfigList(Config.StringDefault("maintenance.paths", ""))
+		MaintenanceIPs = splitConfigList(Config.StringDefault("maintenance.ips", ""))
+		MaintenanceRetryAfter = time.Duration(Config.IntDefault("maintenance.retryafter", 0)) * time.Second
+	})
+}
+
+// Write a 503 Service Unavailable error, in the request format.
+// If retryAfter is non-zero, it is sent (in seconds) as the Retry-After header.
+func (resp *Response) ServiceUnavailable(req *Request, retryAfter time.Duration) {
+	resp.setRetryAfter(retryAfter)
+	resp.writeStatusError(req, http.StatusServiceUnavailable, "Service Unavailable",
+		"The service is temporarily unavailable.  Please try again later.")
+}
+
+// Return true if the server is in maintenance mode and this request may not
+// bypass it.
+func (r *Request) InMaintenance() bool {
+	if !MaintenanceMode {
+		return false
+	}
+	for _, prefix := range MaintenancePaths {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return false
+		}
+	}
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	return !ContainsString(MaintenanceIPs, ip)
+}
+
+// Split a comma-separated config value, dropping empty elements.
+func splitConfigList(value string) []string {
+	var list []string
+	for _, element := range strings.Split(value, ",") {
+		if element = strings.TrimSpace(element); element != "" {
+			list = append(list, element)
+		}
+	}
+	return list
+}
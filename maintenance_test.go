@@ -0,0 +1,35 @@
+package revel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMaintenance(t *testing.T) {
+	MaintenanceMode, MaintenancePaths, MaintenanceIPs = true, []string{"/health"}, []string{"10.0.0.1"}
+	defer func() { MaintenanceMode, MaintenancePaths, MaintenanceIPs = false, nil, nil }()
+
+	testCases := []struct {
+		path, remoteAddr string
+		expected         bool
+	}{
+		{"/", "192.168.0.1:1234", true},
+		{"/health/db", "192.168.0.1:1234", false},
+		{"/", "10.0.0.1:1234", false},
+	}
+	for _, testCase := range testCases {
+		httpRequest, _ := http.NewRequest("GET", testCase.path, nil)
+		httpRequest.RemoteAddr = testCase.remoteAddr
+		if actual := NewRequest(httpRequest).InMaintenance(); actual != testCase.expected {
+			t.Errorf("%s from %s: expected %v", testCase.path, testCase.remoteAddr, testCase.expected)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	NewResponse(recorder).ServiceUnavailable(newTestRequest("json"), 90*time.Second)
+	if recorder.Code != http.StatusServiceUnavailable || recorder.Header().Get("Retry-After") != "90" {
+		t.Errorf("Unexpected response: %d, Retry-After %q", recorder.Code, recorder.Header().Get("Retry-After"))
+	}
+}
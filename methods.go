@@ -0,0 +1,44 @@
+package revel
+
+import (
+	"net/http"
+	"strings"
+)
+
+// The methods advertised in response to a server-wide "OPTIONS *" request.
+var ServerMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+// Return true if this is a server-wide "OPTIONS *" request, rather than one
+// for a specific resource.
+func (r *Request) IsServerOptions() bool {
+	return r.Method == "OPTIONS" && r.RequestURI == "*"
+}
+
+// Write a 204 No Content response to an OPTIONS request, listing the allowed
+// methods in the Allow header.
+func (resp *Response) Options(allowed []string) {
+	resp.setAllow(allowed)
+	resp.Status = http.StatusNoContent
+	resp.WriteHeader(http.StatusNoContent, "")
+}
+
+// Write a 405 Method Not Allowed error, in the request format.
+// The allowed methods are listed in the Allow header, as required.
+func (resp *Response) MethodNotAllowed(req *Request, allowed []string) {
+	resp.setAllow(allowed)
+	resp.writeStatusError(req, http.StatusMethodNotAllowed, "Method Not Allowed",
+		"The "+req.Method+" method is not supported by this resource.")
+}
+
+// Set the Allow header to the given methods, upper-cased and de-duplicated.
+// An empty Allow header is valid: it means the resource allows no methods.
+func (resp *Response) setAllow(allowed []string) {
+	methods := make([]string, 0, len(allowed))
+	for _, method := range allowed {
+		method = strings.ToUpper(strings.TrimSpace(method))
+		if method != "" && !ContainsString(methods, method) {
+			methods = append(methods, method)
+		}
+	}
+	resp.Out.Header().Set("Allow", strings.Join(methods, ", "))
+}
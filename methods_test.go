@@ -0,0 +1,40 @@
+package revel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOptions(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	NewResponse(recorder).Options([]string{"get", "HEAD", "GET", " post"})
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf("Expected a 204, got %d", recorder.Code)
+	}
+	if allow := recorder.Header().Get("Allow"); allow != "GET, HEAD, POST" {
+		t.Errorf("Expected Allow: GET, HEAD, POST, got %q", allow)
+	}
+
+	recorder = httptest.NewRecorder()
+	req := newTestRequest("json")
+	req.Method = "DELETE"
+	NewResponse(recorder).MethodNotAllowed(req, []string{"GET", "HEAD"})
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected a 405, got %d", recorder.Code)
+	}
+	if allow := recorder.Header().Get("Allow"); allow != "GET, HEAD" {
+		t.Errorf("Expected Allow: GET, HEAD, got %q", allow)
+	}
+}
+
+func TestIsServerOptions(t *testing.T) {
+	testCases := map[string]bool{"*": true, "/": false, "/*": false}
+	for uri, expected := range testCases {
+		httpRequest, _ := http.NewRequest("OPTIONS", "/", nil)
+		httpRequest.RequestURI = uri
+		if actual := NewRequest(httpRequest).IsServerOptions(); actual != expected {
+			t.Errorf("%s: expected %v, got %v", uri, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package revel
+
+import (
+	"net/http"
+	"strings"
+)
+
+// The hosts this server serves.  If set, requests for any other host (or whose
+// TLS server name does not match the Host) are answered with 421 Misdirected
+// Request.  A leading "*." matches any subdomain.  e.g. "example.com",
+// "*.example.com"
+// Configured with http.hosts (a comma-separated list) in app.conf.
+var AllowedHosts []string
+
+func init() {
+	OnAppStart(func() {
+		AllowedHosts = splitConfigList(Config.StringDefault("http.hosts", ""))
+	})
+}
+
+// Write a 421 Misdirected Request error, in the request format, for requests
+// for a host that this server does not serve.
+func (resp *Response) MisdirectedRequest(req *Request) {
+	resp.writeStatusError(req, http.StatusMisdirectedRequest, "Misdirected Request",
+		"This server does not serve the requested host.")
+}
+
+// Return true if AllowedHosts is set and the request is for a host that is not
+// in it, or was made over TLS to a different server name than its Host.
+func (r *Request) IsMisdirected() bool {
+	if len(AllowedHosts) == 0 {
+		return false
+	}
+	if r.TLS != nil && r.TLS.ServerName != "" &&
+		!strings.EqualFold(r.TLS.ServerName, hostname(r.Request.Host)) {
+		return true
+	}
+	name := hostname(r.ExternalHost())
+	for _, allowed := range AllowedHosts {
+		allowed = strings.ToLower(allowed)
+		if name == allowed ||
+			strings.HasPrefix(allowed, "*.") && strings.HasSuffix(name, allowed[1:]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package revel
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIsMisdirected(t *testing.T) {
+	defer func() { AllowedHosts = nil }()
+	AllowedHosts = []string{"example.com", "*.example.org"}
+
+	testCases := []struct {
+		host, serverName string
+		expected         bool
+	}{
+		{"example.com", "", false},
+		{"EXAMPLE.com:8080", "", false},
+		{"api.example.org", "", false},
+		{"example.org", "", true},
+		{"evil.com", "", true},
+		{"example.com", "example.com", false},
+		{"example.com", "api.example.org", true},
+	}
+	for _, testCase := range testCases {
+		req := forwardedRequest("203.0.113.9:1234", testCase.host, nil)
+		if testCase.serverName != "" {
+			req.TLS = &tls.ConnectionState{ServerName: testCase.serverName}
+		}
+		if actual := req.IsMisdirected(); actual != testCase.expected {
+			t.Errorf("%+v: expected %v", testCase, testCase.expected)
+		}
+	}
+
+	AllowedHosts = nil
+	if forwardedRequest("203.0.113.9:1234", "evil.com", nil).IsMisdirected() {
+		t.Errorf("Expected every host to be served without AllowedHosts")
+	}
+
+	recorder := httptest.NewRecorder()
+	NewResponse(recorder).MisdirectedRequest(newTestRequest("json"))
+	if recorder.Code != http.StatusMisdirectedRequest {
+		t.Errorf("Expected status 421, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,91 @@
+package revel
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+)
+
+var (
+	// The maximum number of parts in a multipart/form-data body.
+	// Configured with http.multipart.maxparts in app.conf.
+	MaxMultipartParts = 1000
+
+	// The maximum size, in bytes, of a multipart/form-data body parsed into
+	// params (see ParseParams and Request.FormMap).  Uploads streamed by
+	// Request.StreamUpload are limited by MaxUploadSize instead.
+	// Configured with http.multipart.maxsize.
+	MaxMultipartSize int64 = 256 << 20 // 256 MB
+)
+
+// The error returned when a multipart body has more than MaxMultipartParts
+// parts.  Like ErrBodyTooLarge, it results in a 413.
+var ErrTooManyParts = errors.New("too many multipart parts")
+
+func init() {
+	OnAppStart(func() {
+		MaxMultipartParts = Config.IntDefault("http.multipart.maxparts", MaxMultipartParts)
+		MaxMultipartSize = int64(Config.IntDefault("http.multipart.maxsize", int(MaxMultipartSize)))
+	})
+}
+
+// Limit reading the multipart request body to MaxMultipartParts parts and
+// maxSize bytes, so that parsing it fails (with ErrTooManyParts, or an
+// http.MaxBytesError) as soon as either is exceeded.  Returns ErrBodyTooLarge
+// right away if the declared length is already too large.
+func (r *Request) limitMultipart(maxSize int64) error {
+	if size, ok := r.ExpectedBodySize(); ok && size > maxSize {
+		return ErrBodyTooLarge
+	}
+	if r.Body == nil {
+		return nil
+	}
+	r.Body = http.MaxBytesReader(nil, r.Body, maxSize)
+	if _, params, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil && params["boundary"] != "" {
+		delimiter := []byte("\n--" + params["boundary"])
+		r.Body = &partLimiter{r.Body, delimiter, []byte("\n"), 0, MaxMultipartParts}
+	}
+	return nil
+}
+
+// Translate the errors from limitMultipart: a body larger than allowed results
+// in ErrBodyTooLarge.
+func multipartError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return ErrBodyTooLarge
+	}
+	if errors.Is(err, ErrTooManyParts) {
+		return ErrTooManyParts
+	}
+	return err
+}
+
+// A reader of a multipart body that fails once it has read too many parts, by
+// counting the boundary delimiters (which may not occur within the parts).
+type partLimiter struct {
+	io.ReadCloser
+	delimiter  []byte
+	tail       []byte // The end of the body read so far, shorter than a delimiter.
+	delimiters int
+	maxParts   int
+}
+
+func (l *partLimiter) Read(p []byte) (int, error) {
+	n, err := l.ReadCloser.Read(p)
+	window := append(l.tail, p[:n]...)
+	l.delimiters += bytes.Count(window, l.delimiter)
+	if len(window) >= len(l.delimiter) {
+		window = window[len(window)-len(l.delimiter)+1:]
+	}
+	l.tail = append(l.tail[:0], window...)
+
+	// Each part begins with a delimiter, and the body ends with one.  The data
+	// read is withheld, so that the parser can not finish without the error.
+	if l.delimiters > l.maxParts+1 {
+		return 0, ErrTooManyParts
+	}
+	return n, err
+}
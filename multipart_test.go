@@ -0,0 +1,78 @@
+package revel
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"testing"
+)
+
+// A multipart request with the given number of fields, of which the body is
+// read in small chunks, so that delimiters are split across reads.
+func manyPartsRequest(parts int) *Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for i := 0; i < parts; i++ {
+		writer.WriteField("field", "value")
+	}
+	writer.Close()
+
+	httpRequest, _ := http.NewRequest("POST", "/", &chunkedReader{body.Bytes(), 7})
+	httpRequest.Header.Set("Content-Type", writer.FormDataContentType())
+	return NewRequest(httpRequest)
+}
+
+type chunkedReader struct {
+	b    []byte
+	size int
+}
+
+func (r *chunkedReader) Read(p []byte) (int, error) {
+	if len(r.b) == 0 {
+		return 0, io.EOF
+	}
+	if len(p) > r.size {
+		p = p[:r.size]
+	}
+	n := copy(p, r.b)
+	r.b = r.b[n:]
+	return n, nil
+}
+
+func TestMaxMultipartParts(t *testing.T) {
+	defer func(parts int) { MaxMultipartParts = parts }(MaxMultipartParts)
+	MaxMultipartParts = 5
+
+	if values, err := manyPartsRequest(5).FormMap(); err != nil || len(values["field"]) != 5 {
+		t.Errorf("Expected 5 values, got %v (%v)", values, err)
+	}
+	_, err := manyPartsRequest(6).FormMap()
+	if err != ErrTooManyParts {
+		t.Errorf("Expected ErrTooManyParts, got %v", err)
+	}
+	if status, _ := errorStatusCode(err); status != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected ErrTooManyParts to be a 413, got %d", status)
+	}
+	if params := ParseParams(manyPartsRequest(6)); len(params.Values) != 0 {
+		t.Errorf("Expected no params, got %v", params.Values)
+	}
+
+	MaxMultipartParts = 2
+	var dst bytes.Buffer
+	if _, _, _, err := uploadRequest().StreamUpload("photo", &dst); err != ErrTooManyParts {
+		t.Errorf("Expected ErrTooManyParts for the upload, got %v", err)
+	}
+}
+
+func TestMaxMultipartSize(t *testing.T) {
+	defer func(size int64) { MaxMultipartSize = size }(MaxMultipartSize)
+	MaxMultipartSize = 100
+
+	if _, err := manyPartsRequest(10).FormMap(); err != ErrBodyTooLarge {
+		t.Errorf("Expected ErrBodyTooLarge, got %v", err)
+	}
+	if _, err := NewRequest(getMultipartRequest()).FormMap(); err != ErrBodyTooLarge {
+		t.Errorf("Expected ErrBodyTooLarge for the declared length, got %v", err)
+	}
+}
@@ -116,6 +116,18 @@ func (resp *Response) WriteHeader(defaultStatusCode int, defaultContentType stri
 	}
 	resp.Out.Header().Set("Content-Type", resp.ContentType)
 	resp.Out.WriteHeader(resp.Status)
+	resp.wroteHeader = true
+}
+
+// Set the content type of the response to the media type, exactly as given
+// (e.g. a vendor type chosen by negotiation), with "; charset=utf-8" appended
+// to text types that do not specify a charset.
+func (resp *Response) SetContentType(mediaType string) {
+	mediaType = strings.TrimSpace(mediaType)
+	if strings.HasPrefix(strings.ToLower(mediaType), "text/") && !strings.Contains(strings.ToLower(mediaType), "charset=") {
+		mediaType += "; charset=utf-8"
+	}
+	resp.ContentType = mediaType
 }
 
 // Internal bookeeping
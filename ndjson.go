@@ -0,0 +1,39 @@
+package revel
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Streams newline-delimited JSON (NDJSON) values to the response.
+type NDJSONWriter struct {
+	req *Request
+	out http.ResponseWriter
+}
+
+// Start a newline-delimited JSON response, and return a writer for its values.
+// The response is written with Response.Status, or 200 if it is unset.
+func (resp *Response) WriteNDJSON(req *Request) *NDJSONWriter {
+	resp.WriteHeader(http.StatusOK, "application/x-ndjson")
+	return &NDJSONWriter{req, resp.Out}
+}
+
+// Write the value as a line of JSON and flush it to the client.
+// An error is returned if the value can not be marshaled, or if the client has
+// gone away, in which case the producer should stop.
+func (w *NDJSONWriter) Encode(v interface{}) error {
+	if err := w.req.Context().Err(); err != nil {
+		return err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err = w.out.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	if flusher, ok := w.out.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
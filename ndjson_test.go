@@ -0,0 +1,31 @@
+package revel
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteNDJSON(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	req := newTestRequest("json")
+	w := NewResponse(recorder).WriteNDJSON(req)
+	for _, v := range []interface{}{map[string]int{"a": 1}, []string{"b"}, "c"} {
+		if err := w.Encode(v); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	}
+	if expected := "{\"a\":1}\n[\"b\"]\n\"c\"\n"; recorder.Body.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, recorder.Body.String())
+	}
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "application/x-ndjson" || !recorder.Flushed {
+		t.Errorf("Expected a flushed application/x-ndjson response, got %s", contentType)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req.Request = req.Request.WithContext(ctx)
+	if err := w.Encode("d"); err != context.Canceled {
+		t.Errorf("Expected the disconnect to be reported, got %v", err)
+	}
+}
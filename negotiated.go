@@ -0,0 +1,134 @@
+package revel
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Functions applied to values before they are marshaled for a format, in the
+// order they were registered.  Keyed by format name.
+var responseTransformers = map[string][]func(v interface{}) interface{}{}
+
+// Register a function to transform values written in the given format by
+// WriteNegotiated and WriteJSON, before they are marshaled.
+// e.g. to wrap every JSON response in an envelope:
+//
+//     revel.RegisterResponseTransformer("json", func(v interface{}) interface{} {
+//         return map[string]interface{}{"data": v}
+//     })
+//
+// Multiple transformers for a format are applied in registration order, each
+// to the result of the previous one.
+func RegisterResponseTransformer(format string, fn func(v interface{}) interface{}) {
+	responseTransformers[format] = append(responseTransformers[format], fn)
+}
+
+// Apply the transformers registered for the format to the value.
+func transformResponse(format string, v interface{}) interface{} {
+	for _, fn := range responseTransformers[format] {
+		v = fn(v)
+	}
+	return v
+}
+
+// Write the value in the request format, marshaled with the format's Marshal
+// func (see RegisterFormat).  Formats without one (e.g. html, which can not be
+// generated from a value) are written as JSON instead.
+//
+// The response is written with Response.Status if it is set (so a handler may
+// override the status for a call), or else the format's default Status, or
+// 200.  If the value can not be marshaled, an error response is written and
+// the error is returned.
+func (resp *Response) WriteNegotiated(req *Request, v interface{}) error {
+	format := LookupFormat(req.Format)
+	if format == nil || format.Marshal == nil {
+		format = LookupFormat("json")
+	}
+	return resp.writeFormat(req, format, v)
+}
+
+// Write the value as JSON, regardless of the request format.
+// See WriteNegotiated.
+func (resp *Response) WriteJSON(req *Request, v interface{}) error {
+	return resp.writeFormat(req, LookupFormat("json"), v)
+}
+
+// Write the value marshaled by the format, as the media type of the format that
+// the client finds most acceptable (or else its first), unless the response
+// ContentType was already set.
+func (resp *Response) writeFormat(req *Request, format *Format, v interface{}) error {
+	if format == nil || format.Marshal == nil || len(format.MediaTypes) == 0 {
+		err := fmt.Errorf("format %q can not marshal values", req.Format)
+		resp.WriteError(req, err)
+		return err
+	}
+	body, err := format.Marshal(transformResponse(format.Name, v))
+	if err != nil {
+		resp.WriteError(req, err)
+		return err
+	}
+
+	status := format.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if resp.ContentType == "" {
+		mediaType := ResolveAccept(req.Request).Negotiate(format.MediaTypes...)
+		if mediaType == "" {
+			mediaType = format.MediaTypes[0]
+		}
+		resp.SetContentType(mediaType)
+	}
+	resp.WriteHeader(status, resp.ContentType)
+	resp.Out.Write(body)
+	return nil
+}
+
+// Negotiate both the format (among the offered format names) and the locale
+// (among the supported ones) of a response, and add Accept and Accept-Language
+// to its Vary header.
+//
+// The format is the offered one named by the FormatParam query parameter, or
+// else the one with the media type the client finds most acceptable (ties
+// going to the first offered).  ok is false if none is acceptable, and the
+// request should be answered with Response.NotAcceptable.  The locale is
+// matched against Accept-Language (see MatchLocale), falling back to the first
+// supported locale.
+func (r *Request) Negotiate2(resp *Response, offeredFormats, supportedLocales []string) (format, locale string, ok bool) {
+	if resp != nil {
+		resp.AddVary("Accept")
+		if len(supportedLocales) > 0 {
+			resp.AddVary("Accept-Language")
+		}
+	}
+
+	if locale, ok = MatchLocale(r.AcceptLanguages, supportedLocales); !ok && len(supportedLocales) > 0 {
+		locale = supportedLocales[0]
+	}
+
+	if name, ok := formatFromParam(r.Request); ok && ContainsString(offeredFormats, name) {
+		return name, locale, true
+	}
+	var (
+		mediaTypes []string
+		owners     = map[string]string{}
+	)
+	for _, name := range offeredFormats {
+		f := LookupFormat(name)
+		if f == nil {
+			WARN.Printf("Offered format '%s' is not registered", name)
+			continue
+		}
+		for _, mediaType := range f.MediaTypes {
+			if _, ok := owners[mediaType]; !ok {
+				mediaTypes = append(mediaTypes, mediaType)
+				owners[mediaType] = f.Name
+			}
+		}
+	}
+	mediaType := ResolveAccept(r.Request).Negotiate(mediaTypes...)
+	if mediaType == "" {
+		return "", locale, false
+	}
+	return owners[mediaType], locale, true
+}
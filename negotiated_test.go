@@ -0,0 +1,167 @@
+package revel
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteNegotiated(t *testing.T) {
+	type greeting struct {
+		Text string `json:"text" xml:"text"`
+	}
+	testCases := map[string]struct {
+		contentType, body string
+	}{
+		"json": {"application/json", `{"text":"hello"}`},
+		"html": {"application/json", `{"text":"hello"}`},
+		"xml":  {"application/xml", `<greeting><text>hello</text></greeting>`},
+		"txt":  {"text/plain; charset=utf-8", `{hello}`},
+	}
+	for format, expected := range testCases {
+		recorder := httptest.NewRecorder()
+		if err := NewResponse(recorder).WriteNegotiated(newTestRequest(format), greeting{"hello"}); err != nil {
+			t.Errorf("%s: unexpected error: %s", format, err)
+		}
+		if recorder.Code != 200 || recorder.Header().Get("Content-Type") != expected.contentType {
+			t.Errorf("%s: expected 200 %s, got %d %s", format, expected.contentType,
+				recorder.Code, recorder.Header().Get("Content-Type"))
+		}
+		if recorder.Body.String() != expected.body {
+			t.Errorf("%s: expected %s, got %s", format, expected.body, recorder.Body.String())
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	if err := NewResponse(recorder).WriteJSON(newTestRequest("json"), func() {}); err == nil || recorder.Code != 500 {
+		t.Errorf("Expected an unmarshalable value to result in a 500, got %d (%v)", recorder.Code, err)
+	}
+}
+
+func TestResponseTransformers(t *testing.T) {
+	defer func() { responseTransformers = map[string][]func(interface{}) interface{}{} }()
+	RegisterResponseTransformer("json", func(v interface{}) interface{} {
+		return map[string]interface{}{"data": v}
+	})
+	RegisterResponseTransformer("json", func(v interface{}) interface{} {
+		return []interface{}{v}
+	})
+
+	recorder := httptest.NewRecorder()
+	NewResponse(recorder).WriteNegotiated(newTestRequest("json"), 1)
+	if expected := `[{"data":1}]`; recorder.Body.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, recorder.Body.String())
+	}
+	recorder = httptest.NewRecorder()
+	NewResponse(recorder).WriteNegotiated(newTestRequest("txt"), 1)
+	if expected := `1`; recorder.Body.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, recorder.Body.String())
+	}
+}
+
+func TestFormatMarshal(t *testing.T) {
+	defer func(registered []*Format) { formats = registered }(append([]*Format{}, formats...))
+	RegisterFormat(&Format{
+		Name:       "csv",
+		MediaTypes: []string{"text/csv"},
+		Marshal: func(v interface{}) ([]byte, error) {
+			return []byte(strings.Join(v.([]string), ",")), nil
+		},
+	})
+
+	recorder := httptest.NewRecorder()
+	NewResponse(recorder).WriteNegotiated(newTestRequest("csv"), []string{"a", "b"})
+	if recorder.Body.String() != "a,b" || recorder.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.Errorf("Expected the csv marshaler to be used, got %s %q", recorder.Header().Get("Content-Type"), recorder.Body)
+	}
+}
+
+func TestFormatStatus(t *testing.T) {
+	defer func(registered []*Format) { formats = registered }(append([]*Format{}, formats...))
+	json := *LookupFormat("json")
+	json.Status = http.StatusCreated
+	RegisterFormat(&json)
+
+	testCases := []struct {
+		format   string
+		status   int
+		expected int
+	}{
+		{"json", 0, http.StatusCreated},
+		{"json", http.StatusAccepted, http.StatusAccepted},
+		{"xml", 0, http.StatusOK},
+	}
+	for _, testCase := range testCases {
+		recorder := httptest.NewRecorder()
+		resp := NewResponse(recorder)
+		resp.Status = testCase.status
+		resp.WriteNegotiated(newTestRequest(testCase.format), "x")
+		if recorder.Code != testCase.expected {
+			t.Errorf("%+v: expected status %d, got %d", testCase, testCase.expected, recorder.Code)
+		}
+	}
+}
+
+func TestNegotiate2(t *testing.T) {
+	testCases := []struct {
+		accept, acceptLanguage, url string
+		format, locale              string
+		ok                          bool
+	}{
+		{"application/json", "nl-BE, en;q=0.5", "/", "json", "nl", true},
+		{"", "fr", "/", "xml", "en", true},
+		{"text/html", "en-US", "/", "", "en", false},
+		{"text/html", "en-US", "/?format=json", "json", "en", true},
+		{"application/xml, application/json;q=0.9", "", "/", "xml", "en", true},
+	}
+	for _, testCase := range testCases {
+		httpRequest, _ := http.NewRequest("GET", testCase.url, nil)
+		if testCase.accept != "" {
+			httpRequest.Header.Set("Accept", testCase.accept)
+		}
+		httpRequest.Header.Set("Accept-Language", testCase.acceptLanguage)
+		recorder := httptest.NewRecorder()
+		format, locale, ok := NewRequest(httpRequest).Negotiate2(NewResponse(recorder), []string{"xml", "json"}, []string{"en", "nl"})
+		if format != testCase.format || locale != testCase.locale || ok != testCase.ok {
+			t.Errorf("%+v: got %q %q %v", testCase, format, locale, ok)
+		}
+		if vary := recorder.Header().Get("Vary"); vary != "Accept, Accept-Language" {
+			t.Errorf("%+v: unexpected Vary %q", testCase, vary)
+		}
+	}
+}
+
+func TestNegotiatedContentType(t *testing.T) {
+	defer func(registered []*Format) { formats = registered }(append([]*Format{}, formats...))
+	RegisterFormat(&Format{
+		Name:       "jsonapi",
+		MediaTypes: []string{"application/vnd.api+json"},
+		Marshal:    json.Marshal,
+	})
+
+	testCases := []struct {
+		accept, contentType string
+	}{
+		{"application/vnd.api+json", "application/vnd.api+json"},
+		{"text/javascript", "text/javascript; charset=utf-8"},
+		{"application/json", "application/json"},
+	}
+	for _, testCase := range testCases {
+		req := NewRequest(acceptRequest(testCase.accept))
+		recorder := httptest.NewRecorder()
+		NewResponse(recorder).WriteNegotiated(req, map[string]int{"id": 1})
+		if actual := recorder.Header().Get("Content-Type"); actual != testCase.contentType {
+			t.Errorf("%s: expected %s, got %s", testCase.accept, testCase.contentType, actual)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	resp := NewResponse(recorder)
+	resp.SetContentType("text/html; charset=iso-8859-1")
+	resp.WriteNegotiated(newTestRequest("json"), 1)
+	if actual := recorder.Header().Get("Content-Type"); actual != "text/html; charset=iso-8859-1" {
+		t.Errorf("Expected an explicit content type to be kept, got %s", actual)
+	}
+}
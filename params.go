@@ -1,7 +1,9 @@
 package revel
 
 import (
+	"io"
 	"mime/multipart"
+	"net/http"
 	"net/url"
 	"os"
 	"reflect"
@@ -24,6 +26,16 @@ func ParseParams(req *Request) *Params {
 	// Always want the url parameters.
 	values := req.URL.Query()
 
+	// Find the decoder for forms posted in other charsets, if enabled.
+	var decoder func(io.Reader) io.Reader
+	if TranscodeCharsets {
+		var err error
+		if decoder, err = req.charsetDecoder(); err != nil {
+			WARN.Printf("Not parsing request body with charset '%s': %s", req.Charset(), err)
+			return &Params{Values: values}
+		}
+	}
+
 	// Parse the body depending on the content type.
 	switch req.ContentType {
 	case "application/x-www-form-urlencoded":
@@ -31,24 +43,18 @@ func ParseParams(req *Request) *Params {
 		if err := req.ParseForm(); err != nil {
 			WARN.Println("Error parsing request body:", err)
 		} else {
-			for key, vals := range req.Form {
-				for _, val := range vals {
-					values.Add(key, val)
-				}
-			}
+			addValues(values, req.Form, decoder)
 		}
 
 	case "multipart/form-data":
 		// Multipart form.
 		// TODO: Extract the multipart form param so app can set it.
-		if err := req.ParseMultipartForm(32 << 20 /* 32 MB */); err != nil {
+		if err := req.limitMultipart(MaxMultipartSize); err != nil {
 			WARN.Println("Error parsing request body:", err)
+		} else if err := req.ParseMultipartForm(32 << 20 /* 32 MB */); err != nil {
+			WARN.Println("Error parsing request body:", multipartError(err))
 		} else {
-			for key, vals := range req.MultipartForm.Value {
-				for _, val := range vals {
-					values.Add(key, val)
-				}
-			}
+			addValues(values, req.MultipartForm.Value, decoder)
 			files = req.MultipartForm.File
 		}
 	}
@@ -56,6 +62,83 @@ func ParseParams(req *Request) *Params {
 	return &Params{Values: values, Files: files}
 }
 
+// Add the body values to the params, transcoding them first if necessary.
+func addValues(values, bodyValues url.Values, decoder func(io.Reader) io.Reader) {
+	if decoder != nil {
+		var err error
+		if bodyValues, err = transcodeValues(decoder, bodyValues); err != nil {
+			WARN.Println("Error transcoding request body:", err)
+			return
+		}
+	}
+	for key, vals := range bodyValues {
+		for _, val := range vals {
+			values.Add(key, val)
+		}
+	}
+}
+
+// Return the query and form values of the request merged into a single map.
+//
+// Both urlencoded and multipart bodies are parsed, up to MaxBodySize bytes
+// (ErrBodyTooLarge is returned beyond that).  Multipart bodies are also limited
+// to MaxMultipartSize, and MaxMultipartParts parts (beyond which
+// ErrTooManyParts is returned).  For each key, the body values come
+// first, followed by the query values, so the first value is the one posted.
+// The returned map is a copy and may be modified freely.
+func (r *Request) FormMap() (map[string][]string, error) {
+	values, err := r.bodyForm()
+	if err != nil {
+		return nil, err
+	}
+	for key, vals := range r.URL.Query() {
+		values[key] = append(values[key], vals...)
+	}
+	return values, nil
+}
+
+// Return the values of the urlencoded or multipart body, without the query, as
+// described for FormMap.
+func (r *Request) bodyForm() (url.Values, error) {
+	if r.Form == nil && r.Body != nil {
+		if size, ok := r.ExpectedBodySize(); ok && size > MaxBodySize {
+			return nil, ErrBodyTooLarge
+		}
+		r.Body = http.MaxBytesReader(nil, r.Body, MaxBodySize)
+	}
+
+	var (
+		bodyValues url.Values
+		err        error
+	)
+	if r.ContentType == "multipart/form-data" {
+		if r.MultipartForm == nil {
+			if err = r.limitMultipart(MaxMultipartSize); err != nil {
+				return nil, err
+			}
+		}
+		if err = r.ParseMultipartForm(32 << 20 /* 32 MB */); err == nil {
+			bodyValues = r.MultipartForm.Value
+		}
+	} else if err = r.ParseForm(); err == nil {
+		bodyValues = r.PostForm
+	}
+	if err != nil {
+		return nil, multipartError(err)
+	}
+
+	var decoder func(io.Reader) io.Reader
+	if TranscodeCharsets {
+		if decoder, err = r.charsetDecoder(); err != nil {
+			return nil, err
+		}
+	}
+
+	values := make(url.Values)
+	addValues(values, bodyValues, decoder)
+	return values, nil
+}
+
 func (p *Params) Bind(name string, typ reflect.Type) reflect.Value {
 	return Bind(p, name, typ)
 }
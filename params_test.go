@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -105,6 +106,50 @@ func TestMultipartForm(t *testing.T) {
 	}
 }
 
+func TestFormMap(t *testing.T) {
+	httpRequest, _ := http.NewRequest("POST", "/?text1=query&q=1", strings.NewReader("text1=body&a=b"))
+	httpRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	expected := map[string][]string{"text1": {"body", "query"}, "a": {"b"}, "q": {"1"}}
+	if actual, err := NewRequest(httpRequest).FormMap(); err != nil || !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v, got %v (%v)", expected, actual, err)
+	}
+
+	if actual, err := NewRequest(getMultipartRequest()).FormMap(); err != nil || !reflect.DeepEqual(expectedValues, actual) {
+		t.Errorf("Expected %v, got %v (%v)", expectedValues, actual, err)
+	}
+
+	defer func(size int64) { MaxBodySize = size }(MaxBodySize)
+	MaxBodySize = 5
+	if _, err := NewRequest(getMultipartRequest()).FormMap(); err != ErrBodyTooLarge {
+		t.Errorf("Expected ErrBodyTooLarge, got %v", err)
+	}
+}
+
+func TestTranscodeForm(t *testing.T) {
+	TranscodeCharsets = true
+	defer func() { TranscodeCharsets = false }()
+
+	testCases := map[string]string{
+		"iso-8859-1":   "caf\u00e9",
+		"windows-1252": "caf\u00e9 \u20ac",
+		"utf-8":        "caf\u00e9 \u20ac",
+		"x-unknown":    "",
+	}
+	bodies := map[string]string{
+		"iso-8859-1":   "name=caf%E9",
+		"windows-1252": "name=caf%E9+%80",
+		"utf-8":        "name=caf%C3%A9+%E2%82%AC",
+		"x-unknown":    "name=caf%E9",
+	}
+	for charset, expected := range testCases {
+		httpRequest, _ := http.NewRequest("POST", "/", strings.NewReader(bodies[charset]))
+		httpRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset="+charset)
+		if actual := ParseParams(NewRequest(httpRequest)).Get("name"); actual != expected {
+			t.Errorf("%s: expected %q, got %q", charset, expected, actual)
+		}
+	}
+}
+
 func TestResolveAcceptLanguage(t *testing.T) {
 	request := buildHttpRequestWithAcceptLanguage("")
 	if result := ResolveAcceptLanguage(request); result != nil {
@@ -126,6 +171,15 @@ func TestResolveAcceptLanguage(t *testing.T) {
 		}
 	}
 
+	DefaultAcceptLanguages = AcceptLanguages{{"en-US", 1}}
+	request = buildHttpRequestWithAcceptLanguage("")
+	if result := ResolveAcceptLanguage(request); len(result) != 1 || result[0].Language != "en-US" {
+		t.Errorf("Expected the default Accept-Language but it was '%s'", result)
+	} else if result[0].Language = "fr"; DefaultAcceptLanguages[0].Language != "en-US" {
+		t.Errorf("Expected the default Accept-Language to be copied")
+	}
+	DefaultAcceptLanguages = nil
+
 	request = buildHttpRequestWithAcceptLanguage("en;q=0.8,nl;q=0.6,en-AU;q=malformed")
 	if result := ResolveAcceptLanguage(request); len(result) != 3 {
 		t.Errorf("Unexpected Accept-Language values length of %d (expected %d)", len(result), 3)
@@ -0,0 +1,440 @@
+package revel
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// The media types of the PATCH formats understood by Request.ApplyPatch.
+const (
+	JSONPatchMediaType  = "application/json-patch+json"  // RFC 6902
+	MergePatchMediaType = "application/merge-patch+json" // RFC 7386
+)
+
+var patchMediaTypes = []string{JSONPatchMediaType, MergePatchMediaType}
+
+var (
+	ErrMalformedPatch = errors.New("malformed patch")
+	ErrPatchConflict  = errors.New("patch can not be applied")
+)
+
+// Return the patch media type of the request body (JSONPatchMediaType or
+// MergePatchMediaType), or false if it is not a patch document.
+func (r *Request) PatchType() (string, bool) {
+	for _, mediaType := range patchMediaTypes {
+		if r.ContentType == mediaType {
+			return mediaType, true
+		}
+	}
+	return "", false
+}
+
+// Apply the patch in the request body to the target, which must be a pointer to
+// a value that can be encoded as JSON.  The patch semantics are chosen by the
+// Content-Type: a JSON Patch (RFC 6902) or a JSON Merge Patch (RFC 7386).
+//
+// The target is only changed if the whole patch applies.  Errors match
+// ErrUnsupportedMediaType (415) if the body is not a patch, ErrMalformedPatch
+// (400) if it can not be parsed, and ErrPatchConflict (409) if it does not apply
+// to the target (e.g. a path does not exist, or a "test" operation fails).
+func (r *Request) ApplyPatch(target interface{}) error {
+	patchType, ok := r.PatchType()
+	if !ok {
+		return &UnsupportedMediaTypeError{r.ContentType, patchMediaTypes}
+	}
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return errors.New("patch target must be a non-nil pointer")
+	}
+
+	body, err := r.BodyBytes()
+	if err != nil {
+		return err
+	}
+	var patch interface{}
+	if err := decodeJSON(body, &patch); err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformedPatch, err)
+	}
+	original, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+	var doc interface{}
+	if err := decodeJSON(original, &doc); err != nil {
+		return err
+	}
+
+	if patchType == MergePatchMediaType {
+		doc = mergePatch(doc, patch)
+	} else if doc, err = jsonPatch(doc, patch); err != nil {
+		return err
+	}
+
+	patched, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	// Start a struct from the target, so that fields not encoded as JSON are
+	// kept.  Other values (e.g. maps) are decoded afresh, so that the target is
+	// not shared, and removed keys do not survive.
+	result := reflect.New(value.Elem().Type())
+	if result.Elem().Kind() == reflect.Struct {
+		result.Elem().Set(value.Elem())
+		clearJSONFields(result.Elem())
+	}
+	if err := json.Unmarshal(patched, result.Interface()); err != nil {
+		return fmt.Errorf("%w: %v", ErrPatchConflict, err)
+	}
+	value.Elem().Set(result.Elem())
+	return nil
+}
+
+// Zero the fields of a struct that are encoded as JSON, leaving those that are
+// not (unexported, or tagged `json:"-"`) as they are.
+func clearJSONFields(v reflect.Value) {
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		tag := field.Tag.Get("json")
+		switch {
+		case tag == "-":
+		case field.Anonymous && field.Type.Kind() == reflect.Struct && strings.Split(tag, ",")[0] == "":
+			clearJSONFields(v.Field(i)) // Its fields are encoded as if they were the outer struct's.
+		case field.PkgPath == "":
+			v.Field(i).Set(reflect.Zero(field.Type))
+		}
+	}
+}
+
+// Decode JSON, keeping numbers as they are written.
+func decodeJSON(b []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// Apply a JSON Merge Patch to the given document.
+func mergePatch(doc, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	docObject, ok := doc.(map[string]interface{})
+	if !ok {
+		docObject = make(map[string]interface{})
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(docObject, name)
+		} else {
+			docObject[name] = mergePatch(docObject[name], value)
+		}
+	}
+	return docObject
+}
+
+// A JSON Patch operation.
+type patchOperation struct {
+	Op    string
+	Path  *string
+	From  *string
+	Value interface{}
+
+	hasValue bool
+}
+
+// Apply a JSON Patch (an array of operations) to the given document.
+func jsonPatch(doc, patch interface{}) (interface{}, error) {
+	operations, ok := patch.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: a JSON Patch must be an array of operations", ErrMalformedPatch)
+	}
+	for i, element := range operations {
+		operation, err := parsePatchOperation(element)
+		if err != nil {
+			return nil, fmt.Errorf("%w: operation %d: %v", ErrMalformedPatch, i, err)
+		}
+		if doc, err = operation.apply(doc); err != nil {
+			return nil, fmt.Errorf("%w: operation %d (%s %s): %v", ErrPatchConflict, i, operation.Op, *operation.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+func parsePatchOperation(element interface{}) (*patchOperation, error) {
+	object, ok := element.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("not an object")
+	}
+	operation := &patchOperation{}
+	operation.Op, _ = object["op"].(string)
+	if path, ok := object["path"].(string); ok {
+		operation.Path = &path
+	}
+	if from, ok := object["from"].(string); ok {
+		operation.From = &from
+	}
+	operation.Value, operation.hasValue = object["value"]
+
+	switch operation.Op {
+	case "add", "replace", "test":
+		if !operation.hasValue {
+			return nil, fmt.Errorf("%q requires a value", operation.Op)
+		}
+	case "move", "copy":
+		if operation.From == nil {
+			return nil, fmt.Errorf("%q requires from", operation.Op)
+		}
+	case "remove":
+	default:
+		return nil, fmt.Errorf("unknown op %q", operation.Op)
+	}
+	if operation.Path == nil {
+		return nil, errors.New("missing path")
+	}
+	return operation, nil
+}
+
+func (o *patchOperation) apply(doc interface{}) (interface{}, error) {
+	path, err := parsePointer(*o.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch o.Op {
+	case "add":
+		return addValue(doc, path, o.Value)
+	case "remove":
+		if len(path) == 0 {
+			return nil, errors.New("can not remove the whole document")
+		}
+		doc, _, err = removeValue(doc, path)
+		return doc, err
+	case "replace":
+		if len(path) == 0 {
+			return o.Value, nil
+		}
+		if doc, _, err = removeValue(doc, path); err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, o.Value)
+	case "test":
+		value, err := getValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(value, o.Value) {
+			return nil, errors.New("test failed")
+		}
+		return doc, nil
+	}
+
+	from, err := parsePointer(*o.From)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if o.Op == "move" {
+		if *o.Path == *o.From {
+			return doc, nil
+		}
+		if strings.HasPrefix(*o.Path, *o.From+"/") {
+			return nil, errors.New("can not move a value into itself")
+		}
+		if doc, value, err = removeValue(doc, from); err != nil {
+			return nil, err
+		}
+	} else {
+		if value, err = getValue(doc, from); err != nil {
+			return nil, err
+		}
+		value = copyJSON(value)
+	}
+	return addValue(doc, path, value)
+}
+
+// Parse a JSON Pointer (RFC 6901) into its reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+// Return the index of an array element referred to by a JSON Pointer token.
+// If end is true, the index may be one past the last element (also written as
+// "-"), to append to the array.
+func arrayIndex(token string, length int, end bool) (int, error) {
+	if token == "-" && end {
+		return length, nil
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.TrimLeft(token, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i > length || (i == length && !end) {
+		return 0, fmt.Errorf("array index %s out of bounds", token)
+	}
+	return i, nil
+}
+
+func getValue(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("member %q does not exist", token)
+			}
+			doc = value
+		case []interface{}:
+			i, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("can not refer to %q in a scalar value", token)
+		}
+	}
+	return doc, nil
+}
+
+// Add the value at the path, returning the updated document.
+func addValue(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := getValue(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[token] = value
+		return doc, nil
+	case []interface{}:
+		i, err := arrayIndex(token, len(node), true)
+		if err != nil {
+			return nil, err
+		}
+		node = append(node, nil)
+		copy(node[i+1:], node[i:])
+		node[i] = value
+		return setValue(doc, path[:len(path)-1], node), nil
+	}
+	return nil, fmt.Errorf("can not add %q to a scalar value", token)
+}
+
+// Remove the value at the path, returning the updated document and the value.
+func removeValue(doc interface{}, path []string) (interface{}, interface{}, error) {
+	parent, err := getValue(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	token := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		value, ok := node[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("member %q does not exist", token)
+		}
+		delete(node, token)
+		return doc, value, nil
+	case []interface{}:
+		i, err := arrayIndex(token, len(node), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		value := node[i]
+		node = append(node[:i:i], node[i+1:]...)
+		return setValue(doc, path[:len(path)-1], node), value, nil
+	}
+	return nil, nil, fmt.Errorf("can not remove %q from a scalar value", token)
+}
+
+// Replace the value at an existing path, returning the updated document.
+func setValue(doc interface{}, path []string, value interface{}) interface{} {
+	if len(path) == 0 {
+		return value
+	}
+	parent, _ := getValue(doc, path[:len(path)-1])
+	token := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[token] = value
+	case []interface{}:
+		i, _ := strconv.Atoi(token)
+		node[i] = value
+	}
+	return doc
+}
+
+func copyJSON(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(value))
+		for name, member := range value {
+			object[name] = copyJSON(member)
+		}
+		return object
+	case []interface{}:
+		array := make([]interface{}, len(value))
+		for i, element := range value {
+			array[i] = copyJSON(element)
+		}
+		return array
+	}
+	return value
+}
+
+// Return true if the JSON values are equal, comparing numbers by value.
+func jsonEqual(a, b interface{}) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, errA := a.Float64()
+		y, errB := b.Float64()
+		return errA == nil && errB == nil && x == y
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for name, member := range a {
+			if other, ok := b[name]; !ok || !jsonEqual(member, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
@@ -0,0 +1,161 @@
+package revel
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type patchTarget struct {
+	Name  string            `json:"name"`
+	Tags  []string          `json:"tags,omitempty"`
+	Attrs map[string]string `json:"attrs,omitempty"`
+	Count int               `json:"count"`
+}
+
+func patchRequest(contentType, body string) *Request {
+	httpRequest, _ := http.NewRequest("PATCH", "/", strings.NewReader(body))
+	httpRequest.Header.Set("Content-Type", contentType)
+	return NewRequest(httpRequest)
+}
+
+func TestPatchType(t *testing.T) {
+	testCases := map[string]string{
+		"application/json-patch+json":                 JSONPatchMediaType,
+		"application/merge-patch+json; charset=utf-8": MergePatchMediaType,
+		"Application/Merge-Patch+JSON":                MergePatchMediaType,
+		"application/json":                            "",
+	}
+	for contentType, expected := range testCases {
+		actual, ok := patchRequest(contentType, "").PatchType()
+		if actual != expected || ok != (expected != "") {
+			t.Errorf("%q: expected %q, got %q (%v)", contentType, expected, actual, ok)
+		}
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	testCases := []struct {
+		contentType, patch string
+		expected           patchTarget
+		err                error
+	}{
+		{MergePatchMediaType, `{"name": "b", "attrs": {"x": null, "z": "3"}}`,
+			patchTarget{Name: "b", Tags: []string{"t1", "t2"}, Attrs: map[string]string{"y": "2", "z": "3"}, Count: 1}, nil},
+		{MergePatchMediaType, `{"tags": null, "count": 5}`,
+			patchTarget{Name: "a", Attrs: map[string]string{"x": "1", "y": "2"}, Count: 5}, nil},
+		{JSONPatchMediaType, `[
+			{"op": "test", "path": "/count", "value": 1.0},
+			{"op": "replace", "path": "/name", "value": "b"},
+			{"op": "add", "path": "/tags/1", "value": "new"},
+			{"op": "add", "path": "/tags/-", "value": "last"},
+			{"op": "remove", "path": "/attrs/x"},
+			{"op": "copy", "from": "/tags/0", "path": "/attrs/y"},
+			{"op": "move", "from": "/tags/3", "path": "/attrs/a~1b"}
+		]`, patchTarget{Name: "b", Tags: []string{"t1", "new", "t2"}, Attrs: map[string]string{"y": "t1", "a/b": "last"}, Count: 1}, nil},
+		{JSONPatchMediaType, `[{"op": "test", "path": "/name", "value": "z"}]`, patchTarget{}, ErrPatchConflict},
+		{JSONPatchMediaType, `[{"op": "remove", "path": "/tags/5"}]`, patchTarget{}, ErrPatchConflict},
+		{JSONPatchMediaType, `[{"op": "replace", "path": "/count", "value": "text"}]`, patchTarget{}, ErrPatchConflict},
+		{JSONPatchMediaType, `[{"op": "frobnicate", "path": "/name"}]`, patchTarget{}, ErrMalformedPatch},
+		{JSONPatchMediaType, `{"name": "b"}`, patchTarget{}, ErrMalformedPatch},
+		{MergePatchMediaType, `{"name": `, patchTarget{}, ErrMalformedPatch},
+		{"application/json", `{"name": "b"}`, patchTarget{}, ErrUnsupportedMediaType},
+	}
+	for _, testCase := range testCases {
+		original := patchTarget{Name: "a", Tags: []string{"t1", "t2"}, Attrs: map[string]string{"x": "1", "y": "2"}, Count: 1}
+		target := original
+		err := patchRequest(testCase.contentType, testCase.patch).ApplyPatch(&target)
+		if testCase.err != nil {
+			if !errors.Is(err, testCase.err) {
+				t.Errorf("%s: expected %v, got %v", testCase.patch, testCase.err, err)
+			}
+			if !reflect.DeepEqual(target, original) {
+				t.Errorf("%s: expected the target to be unchanged, got %+v", testCase.patch, target)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(target, testCase.expected) {
+			t.Errorf("%s: expected %+v, got %+v (%v)", testCase.patch, testCase.expected, target, err)
+		}
+	}
+}
+
+type patchMetadata struct {
+	Updated string `json:"updated"`
+	etag    string
+}
+
+type hiddenPatchTarget struct {
+	patchMetadata
+	Name    string `json:"name"`
+	Tags    []string
+	Secret  string `json:"-"`
+	version int
+}
+
+func TestApplyPatchKeepsHiddenFields(t *testing.T) {
+	testCases := map[string]string{
+		MergePatchMediaType: `{"name": "b", "Tags": ["x"], "updated": "now"}`,
+		JSONPatchMediaType:  `[{"op": "replace", "path": "/name", "value": "b"}, {"op": "replace", "path": "/Tags", "value": ["x"]}, {"op": "replace", "path": "/updated", "value": "now"}]`,
+	}
+	for contentType, patch := range testCases {
+		target := hiddenPatchTarget{patchMetadata{"then", `"v1"`}, "a", []string{"t1", "t2"}, "s3cret", 7}
+		if err := patchRequest(contentType, patch).ApplyPatch(&target); err != nil {
+			t.Errorf("%s: unexpected error: %s", contentType, err)
+			continue
+		}
+		expected := hiddenPatchTarget{patchMetadata{"now", `"v1"`}, "b", []string{"x"}, "s3cret", 7}
+		if !reflect.DeepEqual(target, expected) {
+			t.Errorf("%s: expected %+v, got %+v", contentType, expected, target)
+		}
+	}
+
+	target := hiddenPatchTarget{Name: "a", Tags: []string{"t1"}, Secret: "s3cret", version: 7}
+	if err := patchRequest(MergePatchMediaType, `{}`).ApplyPatch(&target); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if target.Secret != "s3cret" || target.version != 7 || target.Name != "a" || len(target.Tags) != 1 {
+		t.Errorf("Expected an empty patch to change nothing, got %+v", target)
+	}
+
+	// Fields the patch removes are cleared, rather than kept from the target.
+	target = hiddenPatchTarget{Name: "a", Secret: "s3cret"}
+	if err := patchRequest(JSONPatchMediaType, `[{"op": "remove", "path": "/name"}]`).ApplyPatch(&target); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if target.Name != "" || target.Secret != "s3cret" {
+		t.Errorf("Expected the name to be removed, got %+v", target)
+	}
+}
+
+func TestApplyPatchToMap(t *testing.T) {
+	testCases := []struct {
+		contentType, patch string
+		expected           map[string]interface{}
+	}{
+		{JSONPatchMediaType, `[{"op": "remove", "path": "/a"}]`, map[string]interface{}{"b": "2"}},
+		{MergePatchMediaType, `{"a": null}`, map[string]interface{}{"b": "2"}},
+		{MergePatchMediaType, `{"c": "3"}`, map[string]interface{}{"a": "1", "b": "2", "c": "3"}},
+	}
+	for _, testCase := range testCases {
+		target := map[string]interface{}{"a": "1", "b": "2"}
+		original := target
+		if err := patchRequest(testCase.contentType, testCase.patch).ApplyPatch(&target); err != nil {
+			t.Errorf("%s: unexpected error: %s", testCase.patch, err)
+		} else if !reflect.DeepEqual(target, testCase.expected) {
+			t.Errorf("%s: expected %v, got %v", testCase.patch, testCase.expected, target)
+		}
+		if !reflect.DeepEqual(original, map[string]interface{}{"a": "1", "b": "2"}) {
+			t.Errorf("%s: expected the original map to be unchanged, got %v", testCase.patch, original)
+		}
+	}
+
+	// A patch that fails to decode into the target leaves it unchanged.
+	target := map[string]int{"a": 1}
+	err := patchRequest(MergePatchMediaType, `{"b": "two"}`).ApplyPatch(&target)
+	if !errors.Is(err, ErrPatchConflict) || !reflect.DeepEqual(target, map[string]int{"a": 1}) {
+		t.Errorf("Expected a conflict leaving the map unchanged, got %v (%v)", target, err)
+	}
+}
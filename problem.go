@@ -0,0 +1,115 @@
+package revel
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// A problem details object (RFC 7807), for reporting errors from APIs.
+// Extensions are serialized as additional members alongside the standard ones.
+type Problem struct {
+	Type       string // Defaults to "about:blank".
+	Title      string // Defaults to the status text when Type is "about:blank".
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]interface{}
+}
+
+// The namespace of problem+xml documents. (RFC 7807 appendix A)
+const problemXmlNamespace = "urn:ietf:rfc:7807"
+
+var problemMembers = []string{"type", "title", "status", "detail", "instance"}
+
+// Return the names of the members that are set, in order (standard members
+// first), along with their values.
+func (p Problem) members() (names []string, values map[string]interface{}) {
+	values = map[string]interface{}{}
+	for name, value := range map[string]interface{}{
+		"type": p.Type, "title": p.Title, "status": p.Status, "detail": p.Detail, "instance": p.Instance,
+	} {
+		if value != "" && value != 0 {
+			values[name] = value
+		}
+	}
+	for _, name := range problemMembers {
+		if _, ok := values[name]; ok {
+			names = append(names, name)
+		}
+	}
+
+	var extensions []string
+	for name, value := range p.Extensions {
+		if ContainsString(problemMembers, name) {
+			WARN.Printf("Problem extension '%s' clashes with a standard member; ignoring it", name)
+			continue
+		}
+		extensions = append(extensions, name)
+		values[name] = value
+	}
+	sort.Strings(extensions)
+	return append(names, extensions...), values
+}
+
+func (p Problem) MarshalJSON() ([]byte, error) {
+	_, values := p.members()
+	return json.Marshal(values)
+}
+
+func (p Problem) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Space: problemXmlNamespace, Local: "problem"}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	names, values := p.members()
+	for _, name := range names {
+		if err := e.EncodeElement(values[name], xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// Write the problem as application/problem+json, or as application/problem+xml
+// if the request format is xml.
+//
+// The problem's Status is filled in from the given status if it is unset, and
+// the response is written with the problem's Status.
+func (resp *Response) Problem(req *Request, status int, problem Problem) {
+	if problem.Status == 0 {
+		problem.Status = status
+	}
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+	if problem.Title == "" && problem.Type == "about:blank" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+
+	var (
+		body        []byte
+		contentType string
+		err         error
+	)
+	if req.Format == "xml" {
+		body, err = xml.Marshal(problem)
+		body = append([]byte(xml.Header), body...)
+		contentType = "application/problem+xml"
+	} else {
+		body, err = json.Marshal(problem)
+		contentType = "application/problem+json"
+	}
+	if err != nil {
+		ERROR.Println("Error marshaling problem:", err)
+		resp.writeStatusError(req, http.StatusInternalServerError, "Server Error",
+			"Unable to marshal problem (status "+strconv.Itoa(problem.Status)+")")
+		return
+	}
+
+	resp.Status = problem.Status
+	resp.WriteHeader(problem.Status, contentType)
+	resp.Out.Write(body)
+}
@@ -0,0 +1,38 @@
+package revel
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProblem(t *testing.T) {
+	problem := Problem{
+		Type:       "https://example.com/probs/out-of-credit",
+		Detail:     "Your current balance is 30, but that costs 50.",
+		Extensions: map[string]interface{}{"balance": 30, "status": 200},
+	}
+	testCases := map[string]struct {
+		contentType, body string
+	}{
+		"json": {"application/problem+json",
+			`{"balance":30,"detail":"Your current balance is 30, but that costs 50.","status":403,"type":"https://example.com/probs/out-of-credit"}`},
+		"xml": {"application/problem+xml",
+			`<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<problem xmlns="urn:ietf:rfc:7807"><type>https://example.com/probs/out-of-credit</type><status>403</status>` +
+				`<detail>Your current balance is 30, but that costs 50.</detail><balance>30</balance></problem>`},
+	}
+	for format, expected := range testCases {
+		recorder := httptest.NewRecorder()
+		resp := NewResponse(recorder)
+		resp.Problem(newTestRequest(format), 403, problem)
+		if recorder.Code != 403 || resp.Status != 403 {
+			t.Errorf("%s: expected status 403, got %d", format, recorder.Code)
+		}
+		if actual := recorder.Header().Get("Content-Type"); actual != expected.contentType {
+			t.Errorf("%s: expected content type %s, got %s", format, expected.contentType, actual)
+		}
+		if actual := recorder.Body.String(); actual != expected.body {
+			t.Errorf("%s: expected body\n%s\ngot\n%s", format, expected.body, actual)
+		}
+	}
+}
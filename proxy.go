@@ -0,0 +1,119 @@
+package revel
+
+import (
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Headers that apply only to a single connection, which proxies must not relay.
+// (RFC 7230 section 6.1)
+var hopByHopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// Relay an upstream response to the client: its status, headers, and body.
+// If hopByHopStrip is true, the hop-by-hop headers (including any named by the
+// upstream Connection header) are not copied.  The upstream body is closed.
+// Entries added with Response.AddVia are kept after the upstream Via chain.
+func (resp *Response) CopyFrom(upstream *http.Response, hopByHopStrip bool) error {
+	defer upstream.Body.Close()
+
+	header := resp.Out.Header()
+	for key, values := range upstream.Header {
+		if key == "Via" {
+			header[key] = append(append([]string(nil), values...), header[key]...)
+			continue
+		}
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+	if hopByHopStrip {
+		removeHopByHopHeaders(header, upstream.Header)
+	}
+
+	resp.Status = upstream.StatusCode
+	resp.ContentType = upstream.Header.Get("Content-Type")
+	resp.Out.WriteHeader(resp.Status)
+	resp.wroteHeader = true
+
+	_, err := io.Copy(resp.Out, upstream.Body)
+	return err
+}
+
+// Delete the hop-by-hop headers from the given header, along with any headers
+// named by the Connection header in source.
+func removeHopByHopHeaders(header, source http.Header) {
+	for _, connection := range source["Connection"] {
+		for _, name := range strings.Split(connection, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				header.Del(name)
+			}
+		}
+	}
+	for _, name := range hopByHopHeaders {
+		header.Del(name)
+	}
+}
+
+// Return the entries of the Via header: the intermediaries that the request
+// passed through, in order, e.g. ["1.0 fred", "1.1 p.example.net (Apache/1.1)"].
+// Commas within comments do not separate entries.  (RFC 7230 section 5.7.1)
+func (r *Request) Via() []string {
+	var entries []string
+	for _, field := range r.Header["Via"] {
+		depth, escaped, start := 0, false, 0
+		for i := 0; i <= len(field); i++ {
+			if i < len(field) {
+				c := field[i]
+				switch {
+				case escaped:
+					escaped = false
+					continue
+				case c == '\\' && depth > 0:
+					escaped = true
+					continue
+				case c == '(':
+					depth++
+					continue
+				case c == ')' && depth > 0:
+					depth--
+					continue
+				case c != ',' || depth > 0:
+					continue
+				}
+			}
+			if entry := strings.TrimSpace(field[start:i]); entry != "" {
+				entries = append(entries, entry)
+			}
+			start = i + 1
+		}
+	}
+	return entries
+}
+
+// Append this intermediary to the response Via header, as received by it with
+// the given protocol and named by the pseudonym (or host[:port]).  The protocol
+// name is omitted for HTTP (e.g. "HTTP/1.1" => "1.1").  An optional comment
+// (e.g. the software) is written in parentheses.
+//
+//     resp.AddVia(req.Proto, "gateway", "Revel")  // Via: 1.1 gateway (Revel)
+func (resp *Response) AddVia(protocol, pseudonym string, comment ...string) {
+	if len(protocol) > 5 && strings.EqualFold(protocol[:5], "HTTP/") {
+		protocol = protocol[5:]
+	}
+	value := protocol + " " + pseudonym
+	if len(comment) > 0 && comment[0] != "" {
+		value += " (" + strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(comment[0]) + ")"
+	}
+	resp.Out.Header().Add("Via", value)
+}
@@ -0,0 +1,68 @@
+package revel
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCopyFrom(t *testing.T) {
+	upstream := &http.Response{
+		StatusCode: http.StatusCreated,
+		Header: http.Header{
+			"Content-Type":      {"application/json"},
+			"Connection":        {"keep-alive, X-Hop"},
+			"X-Hop":             {"1"},
+			"Transfer-Encoding": {"chunked"},
+			"X-End-To-End":      {"a", "b"},
+		},
+		Body: ioutil.NopCloser(strings.NewReader(`{"id":1}`)),
+	}
+
+	recorder := httptest.NewRecorder()
+	if err := NewResponse(recorder).CopyFrom(upstream, true); err != nil {
+		t.Fatal(err)
+	}
+	if recorder.Code != http.StatusCreated || recorder.Body.String() != `{"id":1}` {
+		t.Errorf("Unexpected response: %d %s", recorder.Code, recorder.Body)
+	}
+	for _, name := range []string{"Connection", "X-Hop", "Transfer-Encoding"} {
+		if value := recorder.Header().Get(name); value != "" {
+			t.Errorf("Expected hop-by-hop header %s to be stripped, got %q", name, value)
+		}
+	}
+	if values := recorder.Header()["X-End-To-End"]; len(values) != 2 {
+		t.Errorf("Expected end-to-end header values to be copied, got %q", values)
+	}
+}
+
+func TestVia(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/", nil)
+	httpRequest.Header["Via"] = []string{
+		"1.0 fred, 1.1 p.example.net (Apache/1.1, mod_proxy)",
+		`HTTP/2 edge (a \) b), ,`,
+	}
+	expected := []string{"1.0 fred", "1.1 p.example.net (Apache/1.1, mod_proxy)", `HTTP/2 edge (a \) b)`}
+	if actual := NewRequest(httpRequest).Via(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+
+	recorder := httptest.NewRecorder()
+	resp := NewResponse(recorder)
+	resp.AddVia("HTTP/1.1", "gateway", "Revel (beta)")
+	resp.AddVia("2", "edge")
+	resp.AddVia("WebSocket/13", "ws.example.com:8080")
+	upstream := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Via": {"1.1 origin-proxy"}},
+		Body:       ioutil.NopCloser(strings.NewReader("")),
+	}
+	resp.CopyFrom(upstream, true)
+	expected = []string{"1.1 origin-proxy", `1.1 gateway (Revel \(beta\))`, "2 edge", "WebSocket/13 ws.example.com:8080"}
+	if actual := recorder.Header()["Via"]; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
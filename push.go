@@ -0,0 +1,73 @@
+package revel
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Initiate an HTTP/2 server push of the target, for clients that support it.
+// http.ErrNotSupported is returned if the connection does not.
+func (resp *Response) Push(target string, opts *http.PushOptions) error {
+	if pusher, ok := resp.Out.(http.Pusher); ok {
+		return pusher.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// Push each local resource listed by the Link preload headers already set on the
+// response (e.g. by Response.EarlyHints), except those marked nopush.
+// Pushing stops at the first error, which is returned.
+func (resp *Response) PushPreloads() error {
+	for _, target := range preloadTargets(resp.Out.Header()["Link"]) {
+		if err := resp.Push(target, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Return the local paths of the rel=preload links, excluding those marked
+// nopush.  (Only local paths may be pushed.)
+func preloadTargets(links []string) []string {
+	var targets []string
+	for _, link := range splitHeaderList(links) {
+		end := strings.Index(link, ">")
+		if !strings.HasPrefix(link, "<") || end == -1 {
+			continue
+		}
+		target := link[1:end]
+		if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") {
+			continue
+		}
+
+		nopush := false
+		for _, param := range strings.Split(link[end+1:], ";") {
+			name, _, _ := strings.Cut(strings.TrimSpace(param), "=")
+			nopush = nopush || strings.EqualFold(strings.TrimSpace(name), "nopush")
+		}
+		if hasLinkRel(link, "preload") && !nopush {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// Return true if the link (an element of a Link header) has the given relation
+// type among those in its rel parameter.
+func hasLinkRel(link, rel string) bool {
+	end := strings.Index(link, ">")
+	if end == -1 {
+		return false
+	}
+	for _, param := range strings.Split(link[end+1:], ";") {
+		name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		if strings.EqualFold(strings.TrimSpace(name), "rel") {
+			for _, linkRel := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+				if strings.EqualFold(linkRel, rel) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package revel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestPreloadTargets(t *testing.T) {
+	links := []string{
+		`</app.css>; rel=preload; as=style, </app.js>; rel="preload modulepreload"; as=script`,
+		`</font.woff2>; rel=preload; nopush, <https://cdn.example.com/lib.js>; rel=preload`,
+		`</next>; rel=next, <//cdn.example.com/x.js>; rel=preload`,
+	}
+	expected := []string{"/app.css", "/app.js"}
+	if actual := preloadTargets(links); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestPushNotSupported(t *testing.T) {
+	resp := NewResponse(httptest.NewRecorder())
+	resp.Out.Header().Add("Link", "</app.css>; rel=preload")
+	if err := resp.PushPreloads(); err != http.ErrNotSupported {
+		t.Errorf("Expected ErrNotSupported, got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package revel
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The error returned by Request.BindQuery for a parameter that could not be
+// converted to the type of its field.  It is written as a 400 Bad Request.
+type QueryBindError struct {
+	Param, Value string
+	Err          error
+}
+
+func (e *QueryBindError) Error() string {
+	return fmt.Sprintf("query parameter %s: %q: %s", e.Param, e.Value, e.Err)
+}
+
+func (e *QueryBindError) Unwrap() error   { return e.Err }
+func (e *QueryBindError) HttpStatus() int { return http.StatusBadRequest }
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Bind the query parameters to the fields of the struct pointed to by v.
+//
+// Each exported field is bound from the parameter named by its `query` tag, or
+// else by its name (case-insensitively), and fields tagged `query:"-"` are
+// skipped.  Strings, bools, ints, uints, floats, and time.Time (in one of the
+// TimeFormats or RFC 3339) are converted, as are pointers to them and slices of
+// them, which take every value of a repeated parameter.  Fields without a
+// parameter are left as they are.  The first parameter that fails to convert is
+// reported as a *QueryBindError.
+func (r *Request) BindQuery(v interface{}) error {
+	ptr := reflect.ValueOf(v)
+	if ptr.Kind() != reflect.Ptr || ptr.Elem().Kind() != reflect.Struct {
+		return errors.New("BindQuery requires a pointer to a struct")
+	}
+	return bindValues(r.URL.Query(), ptr.Elem())
+}
+
+// Bind the values to the fields of the struct, as described for BindQuery.
+func bindValues(query url.Values, structValue reflect.Value) error {
+	for i := 0; i < structValue.NumField(); i++ {
+		field := structValue.Type().Field(i)
+		name := field.Tag.Get("query")
+		if name == "-" || field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		values, ok := queryValues(query, name)
+		if !ok {
+			continue
+		}
+		if err := setQueryField(structValue.Field(i), values); err != nil {
+			return &QueryBindError{Param: name, Value: strings.Join(values, ","), Err: err}
+		}
+	}
+	return nil
+}
+
+// Return the values of the named parameter: an exact match, or else one that
+// differs only in case.
+func queryValues(query url.Values, name string) ([]string, bool) {
+	if values, ok := query[name]; ok {
+		return values, true
+	}
+	for key, values := range query {
+		if strings.EqualFold(key, name) {
+			return values, true
+		}
+	}
+	return nil, false
+}
+
+func setQueryField(field reflect.Value, values []string) error {
+	switch {
+	case field.Kind() == reflect.Slice:
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setQueryValue(slice.Index(i), value); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	case field.Kind() == reflect.Ptr:
+		elem := reflect.New(field.Type().Elem())
+		if err := setQueryValue(elem.Elem(), values[0]); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+	return setQueryValue(field, values[0])
+}
+
+func setQueryValue(value reflect.Value, s string) error {
+	if value.Type() == timeType {
+		for _, format := range append([]string{time.RFC3339}, TimeFormats...) {
+			if t, err := time.Parse(format, s); err == nil {
+				value.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		return errors.New("not a recognized time")
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return errors.New("not a boolean")
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, value.Type().Bits())
+		if err != nil {
+			return errors.New("not an integer of " + value.Type().String())
+		}
+		value.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, value.Type().Bits())
+		if err != nil {
+			return errors.New("not an unsigned integer of " + value.Type().String())
+		}
+		value.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, value.Type().Bits())
+		if err != nil {
+			return errors.New("not a number")
+		}
+		value.SetFloat(f)
+	default:
+		return errors.New("unsupported field type " + value.Type().String())
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package revel
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type searchQuery struct {
+	Q        string
+	Page     int     `query:"p"`
+	Exact    bool    `query:"exact"`
+	MinScore float64 `query:"min_score"`
+	Tags     []string
+	Ids      []uint16 `query:"id"`
+	Since    time.Time
+	Limit    *int
+	Ignored  string `query:"-"`
+}
+
+func TestBindQuery(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET",
+		"/search?q=go&p=2&exact=true&min_score=0.5&tags=a&tags=b&id=1&id=2&since=2024-05-01T12:00:00Z&limit=10&Ignored=x", nil)
+	var query searchQuery
+	if err := NewRequest(httpRequest).BindQuery(&query); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	limit := 10
+	expected := searchQuery{"go", 2, true, 0.5, []string{"a", "b"}, []uint16{1, 2},
+		time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), &limit, ""}
+	if !reflect.DeepEqual(query, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, query)
+	}
+
+	testCases := map[string]string{
+		"/search?p=two":      "p",
+		"/search?id=1&id=-1": "id",
+		"/search?exact=yes":  "exact",
+		"/search?since=soon": "Since",
+	}
+	for url, param := range testCases {
+		httpRequest, _ := http.NewRequest("GET", url, nil)
+		err := NewRequest(httpRequest).BindQuery(&searchQuery{})
+		var bindErr *QueryBindError
+		if !errors.As(err, &bindErr) || bindErr.Param != param {
+			t.Errorf("%s: expected an error for %s, got %v", url, param, err)
+		} else if status, _ := errorStatusCode(err); status != http.StatusBadRequest {
+			t.Errorf("%s: expected a 400 status, got %d", url, status)
+		}
+	}
+}
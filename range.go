@@ -0,0 +1,173 @@
+package revel
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The most byte ranges a single request may ask for.  Requests for more are
+// answered with the full content instead.  Configured with http.maxranges in
+// app.conf.
+var MaxRanges = 16
+
+func init() {
+	OnAppStart(func() {
+		MaxRanges = Config.IntDefault("http.maxranges", MaxRanges)
+	})
+}
+
+type byteRange struct {
+	start, end int64 // Inclusive.
+}
+
+// Guard against pathological Range headers before serving the content with
+// http.ServeContent, which answers multi-range requests with a
+// multipart/byteranges response.
+//
+// Overlapping and adjacent ranges are coalesced (which also puts descending
+// ranges in order), as recommended by RFC 7233 section 6.1.  If there are still
+// more than MaxRanges left, the Range header is dropped so that the full
+// content is served.  Headers that can not be parsed are left to ServeContent.
+func limitRanges(req *Request, content io.Seeker) {
+	header := req.Header.Get("Range")
+	if header == "" {
+		return
+	}
+	size, err := content.Seek(0, io.SeekEnd)
+	if err != nil {
+		return
+	}
+	if _, err = content.Seek(0, io.SeekStart); err != nil {
+		return
+	}
+	ranges, ok := parseByteRanges(header, size)
+	if !ok || len(ranges) == 0 {
+		return
+	}
+	ranges = coalesceRanges(ranges)
+	if len(ranges) > MaxRanges {
+		WARN.Printf("Ignoring a Range header with %d ranges (more than %d)", len(ranges), MaxRanges)
+		req.Header.Del("Range")
+		return
+	}
+	specs := make([]string, len(ranges))
+	for i, r := range ranges {
+		specs[i] = strconv.FormatInt(r.start, 10) + "-" + strconv.FormatInt(r.end, 10)
+	}
+	req.Header.Set("Range", "bytes="+strings.Join(specs, ","))
+}
+
+// Parse the satisfiable ranges of a "bytes=" Range header, for content of the
+// given size.
+func parseByteRanges(header string, size int64) (ranges []byteRange, ok bool) {
+	if !strings.HasPrefix(header, "bytes=") {
+		return nil, false
+	}
+	for _, spec := range strings.Split(header[len("bytes="):], ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		first, last, found := strings.Cut(spec, "-")
+		if !found {
+			return nil, false
+		}
+		var r byteRange
+		if first == "" {
+			// A suffix range: the last n bytes.
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || n < 0 {
+				return nil, false
+			}
+			if n == 0 || size == 0 {
+				continue
+			}
+			if n > size {
+				n = size
+			}
+			r = byteRange{size - n, size - 1}
+		} else {
+			start, err := strconv.ParseInt(first, 10, 64)
+			if err != nil || start < 0 {
+				return nil, false
+			}
+			r = byteRange{start, size - 1}
+			if last != "" {
+				end, err := strconv.ParseInt(last, 10, 64)
+				if err != nil || end < start {
+					return nil, false
+				}
+				if end < size-1 {
+					r.end = end
+				}
+			}
+			if start >= size {
+				continue
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, true
+}
+
+// Sort the ranges and merge those that overlap or are adjacent.
+func coalesceRanges(ranges []byteRange) []byteRange {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].start < ranges[j].start })
+	merged := ranges[:1]
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r.start <= last.end+1 {
+			if r.end > last.end {
+				last.end = r.end
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// Parse the Content-Range header of a request that uploads part of a resource
+// (e.g. a chunk of a resumable upload): "bytes <start>-<end>/<total>", where
+// the total may be "*" if it is not yet known, in which case it is -1.
+// ok is false if the header is absent or malformed, or the range is not valid
+// (e.g. it ends before it starts, or beyond the total), and the request should
+// be answered with a 400.
+func (r *Request) ContentRange() (start, end, total int64, ok bool) {
+	header := strings.TrimSpace(r.Header.Get("Content-Range"))
+	if !strings.HasPrefix(header, "bytes ") {
+		return 0, 0, 0, false
+	}
+	spec, length, found := strings.Cut(strings.TrimSpace(header[len("bytes "):]), "/")
+	if !found {
+		return 0, 0, 0, false
+	}
+	first, last, found := strings.Cut(spec, "-")
+	if !found {
+		return 0, 0, 0, false
+	}
+	start, startErr := parseNonNegative(first)
+	end, endErr := parseNonNegative(last)
+	if startErr != nil || endErr != nil || end < start {
+		return 0, 0, 0, false
+	}
+
+	total = -1
+	if length != "*" {
+		var err error
+		if total, err = parseNonNegative(length); err != nil || end >= total {
+			return 0, 0, 0, false
+		}
+	}
+	return start, end, total, true
+}
+
+// Parse a decimal integer with no sign.
+func parseNonNegative(s string) (int64, error) {
+	if s == "" || s[0] < '0' || s[0] > '9' {
+		return 0, strconv.ErrSyntax
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
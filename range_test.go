@@ -0,0 +1,84 @@
+package revel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLimitRanges(t *testing.T) {
+	defer func(max int) { MaxRanges = max }(MaxRanges)
+	MaxRanges = 3
+
+	testCases := map[string]string{
+		"bytes=0-9":                   "bytes=0-9",
+		"bytes=50-59, 0-9":            "bytes=0-9,50-59",
+		"bytes=0-9,5-20,21-30":        "bytes=0-30",
+		"bytes=-10":                   "bytes=90-99",
+		"bytes=90-":                   "bytes=90-99",
+		"bytes=0-9,20-29,40-49,60-69": "",
+		"bytes=0-9,200-300":           "bytes=0-9",
+		"bytes=200-300":               "bytes=200-300",
+		"bytes=9-0":                   "bytes=9-0",
+		"items=0-9":                   "items=0-9",
+	}
+	for header, expected := range testCases {
+		httpRequest, _ := http.NewRequest("GET", "/", nil)
+		httpRequest.Header.Set("Range", header)
+		req := NewRequest(httpRequest)
+		limitRanges(req, strings.NewReader(strings.Repeat("x", 100)))
+		if actual := req.Header.Get("Range"); actual != expected {
+			t.Errorf("%q: expected %q, got %q", header, expected, actual)
+		}
+	}
+}
+
+func TestMultipleRanges(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/", nil)
+	httpRequest.Header.Set("Range", "bytes=10-19,0-4")
+	recorder := httptest.NewRecorder()
+	result := &BinaryResult{Reader: strings.NewReader(strings.Repeat("0123456789", 5)), Name: "digits", Length: -1}
+	result.Apply(NewRequest(httpRequest), NewResponse(recorder))
+
+	if recorder.Code != http.StatusPartialContent {
+		t.Errorf("Expected a 206, got %d", recorder.Code)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "multipart/byteranges; boundary=") {
+		t.Errorf("Expected a multipart/byteranges response, got %s", contentType)
+	}
+	for _, contentRange := range []string{"bytes 0-4/50", "bytes 10-19/50"} {
+		if !strings.Contains(recorder.Body.String(), contentRange) {
+			t.Errorf("Expected a part with Content-Range: %s, got:\n%s", contentRange, recorder.Body)
+		}
+	}
+}
+
+func TestContentRange(t *testing.T) {
+	testCases := []struct {
+		header            string
+		start, end, total int64
+		ok                bool
+	}{
+		{"bytes 0-499/1234", 0, 499, 1234, true},
+		{"bytes 500-1233/1234", 500, 1233, 1234, true},
+		{"bytes 1000-1999/*", 1000, 1999, -1, true},
+		{"bytes 0-1234/1234", 0, 0, 0, false},
+		{"bytes 500-499/1234", 0, 0, 0, false},
+		{"bytes */1234", 0, 0, 0, false},
+		{"bytes -1-5/10", 0, 0, 0, false},
+		{"bytes +1-5/10", 0, 0, 0, false},
+		{"bytes 0-5", 0, 0, 0, false},
+		{"items 0-5/10", 0, 0, 0, false},
+		{"", 0, 0, 0, false},
+	}
+	for _, testCase := range testCases {
+		httpRequest, _ := http.NewRequest("PUT", "/upload", nil)
+		httpRequest.Header.Set("Content-Range", testCase.header)
+		start, end, total, ok := NewRequest(httpRequest).ContentRange()
+		if start != testCase.start || end != testCase.end || total != testCase.total || ok != testCase.ok {
+			t.Errorf("%q: expected %d-%d/%d %v, got %d-%d/%d %v", testCase.header,
+				testCase.start, testCase.end, testCase.total, testCase.ok, start, end, total, ok)
+		}
+	}
+}
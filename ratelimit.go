@@ -0,0 +1,54 @@
+package revel
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Set the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers
+// (draft-ietf-httpapi-ratelimit-headers), so that clients may throttle
+// themselves: the quota, how much of it is left, and the time until it resets.
+// (Reset is sent as a whole number of seconds, rounded up.)
+func (resp *Response) SetRateLimit(limit, remaining int, reset time.Duration) {
+	if remaining < 0 {
+		remaining = 0
+	}
+	header := resp.Out.Header()
+	header.Set("RateLimit-Limit", strconv.Itoa(limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(remaining))
+	header.Set("RateLimit-Reset", strconv.FormatInt(ceilSeconds(reset), 10))
+}
+
+// Write a 429 Too Many Requests error, in the request format.
+// If retryAfter is non-zero, it is sent (in seconds) as the Retry-After header.
+// If the rate limit headers were set (see SetRateLimit), they are made
+// consistent with the error: none of the quota remains, and it resets when the
+// client may retry.
+func (resp *Response) TooManyRequests(req *Request, retryAfter time.Duration) {
+	header := resp.Out.Header()
+	resp.setRetryAfter(retryAfter)
+	if header.Get("RateLimit-Limit") != "" {
+		header.Set("RateLimit-Remaining", "0")
+		if retryAfter > 0 {
+			header.Set("RateLimit-Reset", strconv.FormatInt(ceilSeconds(retryAfter), 10))
+		}
+	}
+	resp.writeStatusError(req, http.StatusTooManyRequests, "Too Many Requests",
+		"Too many requests have been made.  Please try again later.")
+}
+
+// Set the Retry-After header to the duration, in seconds, if it is non-zero.
+func (resp *Response) setRetryAfter(retryAfter time.Duration) {
+	if retryAfter > 0 {
+		resp.Out.Header().Set("Retry-After", strconv.FormatInt(ceilSeconds(retryAfter), 10))
+	}
+}
+
+// Return the duration in whole seconds, rounded up.
+func ceilSeconds(d time.Duration) int64 {
+	if d <= 0 {
+		return 0
+	}
+	return int64((d + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,26 @@
+package revel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	resp := NewResponse(recorder)
+	resp.SetRateLimit(100, 42, 1500*time.Millisecond)
+	header := recorder.Header()
+	if header.Get("RateLimit-Limit") != "100" || header.Get("RateLimit-Remaining") != "42" || header.Get("RateLimit-Reset") != "2" {
+		t.Errorf("Unexpected rate limit headers %v", header)
+	}
+
+	resp.TooManyRequests(newTestRequest("json"), 30*time.Second)
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429, got %d", recorder.Code)
+	}
+	if header.Get("Retry-After") != "30" || header.Get("RateLimit-Remaining") != "0" || header.Get("RateLimit-Reset") != "30" {
+		t.Errorf("Unexpected headers for the 429 %v", header)
+	}
+}
@@ -0,0 +1,142 @@
+package revel
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Validate a redirect target (e.g. a "return to" parameter), to prevent open
+// redirects.
+//
+// Relative targets are resolved against the request URL and returned as a path
+// (with any query and fragment), under the request's ForwardedPrefix, unless
+// they are already under it (e.g. a "next" parameter taken from the URL that
+// the client sees).  Absolute targets are only allowed if they are http or
+// https URLs for one of the allowed hosts (compared case-insensitively, with or
+// without the port).  Protocol-relative URLs ("//evil.com"), URLs with
+// user info, backslashes, or control characters are always rejected.
+func SafeRedirectURL(req *Request, target string, allowedHosts []string) (string, bool) {
+	target = strings.TrimSpace(target)
+	if target == "" || strings.ContainsAny(target, "\\") || strings.HasPrefix(target, "//") {
+		return "", false
+	}
+	for _, c := range target {
+		if c < 0x20 || c == 0x7f {
+			return "", false
+		}
+	}
+	u, err := url.Parse(target)
+	if err != nil || u.User != nil || u.Opaque != "" {
+		return "", false
+	}
+
+	if u.Scheme == "" && u.Host == "" {
+		base := &url.URL{Path: "/"}
+		if req != nil && req.URL != nil {
+			base = &url.URL{Path: req.URL.Path}
+		}
+		resolved := base.ResolveReference(u)
+		if strings.HasPrefix(resolved.Path, "//") {
+			return "", false
+		}
+		return req.externalPath(resolved.RequestURI()) + fragment(resolved), true
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", false
+	}
+	for _, allowed := range allowedHosts {
+		if strings.EqualFold(u.Host, allowed) || strings.EqualFold(u.Hostname(), allowed) {
+			return u.String(), true
+		}
+	}
+	return "", false
+}
+
+func fragment(u *url.URL) string {
+	if u.Fragment == "" {
+		return ""
+	}
+	return "#" + u.EscapedFragment()
+}
+
+// Redirect to the given URL, with the given status (302 Found if it is 0).
+func (resp *Response) Redirect(req *Request, target string, status int) {
+	if status == 0 {
+		status = http.StatusFound
+	}
+	resp.Out.Header().Set("Location", target)
+	resp.WriteHeader(status, "")
+}
+
+// Redirect to the target if SafeRedirectURL allows it, or else to the fallback.
+func (resp *Response) SafeRedirect(req *Request, target, fallback string, allowedHosts []string, status int) {
+	if safe, ok := SafeRedirectURL(req, target, allowedHosts); ok {
+		target = safe
+	} else {
+		WARN.Printf("Refusing to redirect to '%s'; redirecting to '%s' instead", target, fallback)
+		target = fallback
+	}
+	resp.Redirect(req, target, status)
+}
+
+// Redirect (with a 301) to the same URL on the canonical host, if the host the
+// client requested is a different one.  (e.g. "www.example.com" to
+// "example.com")  The scheme, path (with any ForwardedPrefix), and query are
+// preserved.
+//
+// Host names are compared case-insensitively.  If the canonical host has a port
+// (other than the default for the scheme), the requested port must match too;
+// otherwise any port on the canonical host name is accepted.  Returns true if
+// the response was redirected.
+func (resp *Response) CanonicalHostRedirect(req *Request, canonicalHost string) bool {
+	scheme := req.Scheme()
+	canonicalName, canonicalPort := splitHostPort(strings.ToLower(canonicalHost), scheme)
+	name, port := splitHostPort(req.ExternalHost(), scheme)
+	if name == canonicalName && (canonicalPort == "" || port == canonicalPort) {
+		return false
+	}
+
+	target := scheme + "://" + canonicalName
+	if canonicalPort != "" {
+		target += ":" + canonicalPort
+	}
+	resp.Redirect(req, target+req.ForwardedPrefix()+req.URL.RequestURI(), http.StatusMovedPermanently)
+	return true
+}
+
+// Set the Content-Location header to the URL of the representation in the
+// response (e.g. the resource a POST created, or the negotiated variant).
+// A relative URL is resolved against the request URL and set as a path (under
+// any ForwardedPrefix, as for SafeRedirectURL).  An error is returned for malformed URLs, and absolute
+// ones that are not http or https or that have user info.  Any fragment is
+// dropped.
+func (resp *Response) SetContentLocation(req *Request, location string) error {
+	location = strings.TrimSpace(location)
+	for _, c := range location {
+		if c < 0x20 || c == 0x7f {
+			return fmt.Errorf("invalid Content-Location %q", location)
+		}
+	}
+	u, err := url.Parse(location)
+	if err != nil || location == "" || u.User != nil || u.Opaque != "" {
+		return fmt.Errorf("invalid Content-Location %q", location)
+	}
+	u.Fragment, u.RawFragment = "", ""
+
+	if u.Scheme == "" && u.Host == "" {
+		base := &url.URL{Path: "/"}
+		if req != nil && req.URL != nil {
+			base = &url.URL{Path: req.URL.Path}
+		}
+		location = req.externalPath(base.ResolveReference(u).RequestURI())
+	} else if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid Content-Location %q", location)
+	} else {
+		location = u.String()
+	}
+	resp.Out.Header().Set("Content-Location", location)
+	return nil
+}
@@ -0,0 +1,164 @@
+package revel
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"runtime/debug"
+	"strings"
+)
+
+// Write an error response for the given error.
+//
+// Errors matching one of the registered sentinels (see RegisterErrorStatus), or
+// implementing HttpStatusError, are written with the corresponding status and
+// their message.  Any other error results in a 500.  Its detail is logged, but
+// only shown to the client in dev mode.
+func (resp *Response) WriteError(req *Request, err error) {
+	var mediaTypeErr *UnsupportedMediaTypeError
+	if errors.As(err, &mediaTypeErr) {
+		resp.UnsupportedMediaType(req, mediaTypeErr.Accepted...)
+		return
+	}
+
+	status, ok := errorStatusCode(err)
+	if !ok {
+		ERROR.Println("Unhandled error:", err)
+		description := http.StatusText(http.StatusInternalServerError)
+		if DevMode {
+			description = err.Error()
+		}
+		resp.writeStatusError(req, http.StatusInternalServerError, "Server Error", description)
+		return
+	}
+	resp.writeStatusError(req, status, http.StatusText(status), err.Error())
+}
+
+// Write a 415 Unsupported Media Type error, in the request format.
+// The media types that the endpoint does accept are listed in the Accept-Patch
+// header (for PATCH requests) or the Accept-Post header (for all others).
+func (resp *Response) UnsupportedMediaType(req *Request, accepted ...string) {
+	if len(accepted) > 0 {
+		header := "Accept-Post"
+		if req.Method == "PATCH" {
+			header = "Accept-Patch"
+		}
+		resp.Out.Header().Set(header, strings.Join(accepted, ", "))
+	}
+	description := fmt.Sprintf("The request content type (%s) is not supported.", req.ContentType)
+	if len(accepted) > 0 {
+		description += "  Supported types: " + strings.Join(accepted, ", ")
+	}
+	resp.writeStatusError(req, http.StatusUnsupportedMediaType, "Unsupported Media Type", description)
+}
+
+// Write a 406 Not Acceptable error, listing the available media types (by
+// default, those of the registered formats) so that the client may choose one.
+//
+// The list is written as a problem+json or problem+xml document with an
+// "available" member, if the client accepts either, or else as plain text.
+func (resp *Response) NotAcceptable(req *Request, available []string) {
+	if available == nil {
+		for _, format := range formats {
+			available = append(available, format.MediaTypes...)
+		}
+	}
+	detail := "None of the available representations is acceptable."
+	problem := Problem{
+		Detail:     detail,
+		Extensions: map[string]interface{}{"available": available},
+	}
+
+	switch ResolveAccept(req.Request).Negotiate("application/problem+json", "application/json",
+		"application/problem+xml", "application/xml") {
+	case "application/problem+json", "application/json":
+		jsonReq := *req
+		jsonReq.Format = "json"
+		resp.Problem(&jsonReq, http.StatusNotAcceptable, problem)
+	case "application/problem+xml", "application/xml":
+		xmlReq := *req
+		xmlReq.Format = "xml"
+		resp.Problem(&xmlReq, http.StatusNotAcceptable, problem)
+	default:
+		resp.Status = http.StatusNotAcceptable
+		resp.WriteHeader(http.StatusNotAcceptable, "text/plain; charset=utf-8")
+		fmt.Fprintf(resp.Out, "%s\n\nAvailable representations:\n", detail)
+		for _, mediaType := range available {
+			fmt.Fprintf(resp.Out, "  %s\n", mediaType)
+		}
+	}
+}
+
+// Recover from a panic in the calling handler and render a 500 error page in
+// the request format.  It must be deferred directly:
+//
+//     defer c.Response.RecoverAndRender(c.Request)
+//
+// The panic value is only shown to the client in dev mode.  If the response
+// headers were already written, the panic is logged and the response is left
+// as it is.
+func (resp *Response) RecoverAndRender(req *Request) {
+	err := recover()
+	if err == nil {
+		return
+	}
+
+	ERROR.Print(err, "\n", string(debug.Stack()))
+	if resp.wroteHeader {
+		ERROR.Println("Response already started; unable to render the error page")
+		return
+	}
+
+	description := http.StatusText(http.StatusInternalServerError)
+	if DevMode {
+		description = fmt.Sprint(err)
+	}
+	resp.writeStatusError(req, http.StatusInternalServerError, "Panic", description)
+}
+
+type xmlError struct {
+	XMLName xml.Name `xml:"error"`
+	Type    string   `xml:"type"`
+	Message string   `xml:"message"`
+}
+
+// Write the given status along with a short error message in the request format.
+// The errors/<status>.<format> template is used if there is one; otherwise a
+// minimal body is generated.
+func (resp *Response) writeStatusError(req *Request, status int, title, description string) {
+	resp.Status = status
+	revelError := &Error{Title: title, Description: description}
+	if MainTemplateLoader != nil {
+		templatePath := fmt.Sprintf("errors/%d.%s", status, req.Format)
+		if _, err := MainTemplateLoader.Template(templatePath); err == nil {
+			ErrorResult{Error: revelError}.Apply(req, resp)
+			return
+		}
+	}
+
+	var (
+		body        []byte
+		contentType string
+	)
+	switch req.Format {
+	case "json":
+		body, _ = json.Marshal(map[string]string{"type": title, "message": description})
+		contentType = "application/json"
+	case "xml":
+		body, _ = xml.Marshal(xmlError{Type: title, Message: description})
+		contentType = "application/xml"
+	case "txt":
+		body = []byte(revelError.Error())
+		contentType = "text/plain"
+	default:
+		body = []byte(fmt.Sprintf("<html><head><title>%s</title></head><body><h1>%s</h1><p>%s</p></body></html>",
+			template.HTMLEscapeString(title), template.HTMLEscapeString(title),
+			template.HTMLEscapeString(description)))
+		contentType = "text/html"
+	}
+	resp.WriteHeader(status, contentType)
+	resp.Out.Write(body)
+}
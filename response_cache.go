@@ -0,0 +1,331 @@
+package revel
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Return a key identifying the representation this request asks for, suitable
+// for caching responses.
+//
+// It combines the method, path, sorted query string, the negotiated format and
+// locale, and the values of the given request headers (the dimensions that the
+// response varies on, as listed in its Vary header; e.g. "Accept-Encoding").
+func (r *Request) CacheKey(varyOn ...string) string {
+	h := sha1.New()
+	write := func(s string) {
+		io.WriteString(h, s)
+		h.Write([]byte{0})
+	}
+
+	write(r.Method)
+	write(r.URL.Path)
+	write(r.URL.Query().Encode()) // Encode sorts by key.
+	write(r.Format)
+	write(r.Locale)
+
+	names := make([]string, len(varyOn))
+	for i, name := range varyOn {
+		names[i] = http.CanonicalHeaderKey(strings.TrimSpace(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		write(name)
+		write(strings.Join(r.Header[name], ","))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// A cache of rendered responses, for read-heavy endpoints.  (see Wrap)
+//
+// Responses are keyed by Request.CacheKey, varying on both the configured
+// headers and those the response lists in its Vary header, so that one
+// representation is never served in place of another.  A response is fresh for
+// TTL after it is rendered.  For StaleWhileRevalidate after that, it is still
+// served, while a fresh copy is rendered in the background.
+//
+// At most MaxEntries responses are kept, evicting the least recently used
+// beyond that, and expired ones are removed as new responses are stored.
+type ResponseCache struct {
+	TTL                  time.Duration
+	StaleWhileRevalidate time.Duration
+	VaryOn               []string // Request headers that every response varies on.
+	MaxEntries           int      // The most responses (and resources' Vary headers) kept, or 0 for no limit.
+
+	mu         sync.Mutex
+	entries    *lruMap // The *cachedResponse for each key.
+	varies     *lruMap // The Vary headers ([]string) seen for each resource.
+	refreshing map[string]bool
+	swept      time.Time // When expired entries were last removed.
+	now        func() time.Time
+}
+
+// The MaxEntries of a ResponseCache made by NewResponseCache.
+const DefaultResponseCacheMaxEntries = 10000
+
+type cachedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+	stored time.Time
+}
+
+func NewResponseCache(ttl, staleWhileRevalidate time.Duration, varyOn ...string) *ResponseCache {
+	return &ResponseCache{
+		TTL:                  ttl,
+		StaleWhileRevalidate: staleWhileRevalidate,
+		VaryOn:               varyOn,
+		MaxEntries:           DefaultResponseCacheMaxEntries,
+		entries:              newLRUMap(),
+		varies:               newLRUMap(),
+		refreshing:           make(map[string]bool),
+		now:                  time.Now,
+	}
+}
+
+// Wrap a handler so that its responses are cached.
+//
+// Only successful (200) responses to GET and HEAD requests are stored, and not
+// those that set cookies, or are marked private, no-store, or Vary: *.  Requests
+// with Cache-Control: no-cache or no-store bypass the cache (and no-cache ones
+// refresh it).  Cached responses are served with an Age header, and stale ones
+// with a 110 Warning.
+func (c *ResponseCache) Wrap(handler func(req *Request, resp *Response)) func(req *Request, resp *Response) {
+	return func(req *Request, resp *Response) {
+		if req.Method != "GET" && req.Method != "HEAD" {
+			handler(req, resp)
+			return
+		}
+		directives := req.CacheControl()
+		if directives.NoStore {
+			handler(req, resp)
+			return
+		}
+
+		resource := req.CacheKey()
+		key := req.CacheKey(c.varyOn(resource)...)
+		if !directives.NoCache {
+			if entry, stale, ok := c.lookup(key); ok {
+				if stale {
+					c.refresh(handler, req, resource, key)
+				}
+				c.replay(resp, entry, stale)
+				return
+			}
+		}
+
+		entry := c.render(handler, req, resource)
+		c.replay(resp, entry, false)
+	}
+}
+
+// Return the headers that responses for the resource have varied on.
+func (c *ResponseCache) varyOn(resource string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	vary, _ := c.varies.get(resource)
+	varyOn := append([]string(nil), c.VaryOn...)
+	if vary != nil {
+		varyOn = append(varyOn, vary.([]string)...)
+	}
+	return varyOn
+}
+
+// Find a cached response, reporting whether it is stale.
+func (c *ResponseCache) lookup(key string) (entry *cachedResponse, stale, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.entries.get(key)
+	if !ok {
+		return nil, false, false
+	}
+	entry = value.(*cachedResponse)
+	age := c.now().Sub(entry.stored)
+	if age >= c.TTL+c.StaleWhileRevalidate {
+		c.entries.delete(key)
+		return nil, false, false
+	}
+	return entry, age >= c.TTL, true
+}
+
+// Render a fresh copy of the stale response in the background, unless one is
+// already being rendered.
+func (c *ResponseCache) refresh(handler func(*Request, *Response), req *Request, resource, key string) {
+	c.mu.Lock()
+	if c.refreshing[key] {
+		c.mu.Unlock()
+		return
+	}
+	c.refreshing[key] = true
+	c.mu.Unlock()
+
+	clone := req.Clone(context.Background())
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				ERROR.Println("Panic refreshing cached response:", err)
+			}
+			c.mu.Lock()
+			delete(c.refreshing, key)
+			c.mu.Unlock()
+		}()
+		c.render(handler, clone, resource)
+	}()
+}
+
+// Run the handler into a buffer, and store the result if it may be cached.
+func (c *ResponseCache) render(handler func(*Request, *Response), req *Request, resource string) *cachedResponse {
+	buffer := &bufferedResponseWriter{header: make(http.Header)}
+	handler(req, NewResponse(buffer))
+	if buffer.status == 0 {
+		buffer.status = http.StatusOK
+	}
+	entry := &cachedResponse{buffer.status, buffer.header, buffer.body.Bytes(), c.now()}
+	if !isCacheable(entry) {
+		return entry
+	}
+
+	vary := splitHeaderList(entry.header["Vary"])
+	key := req.CacheKey(append(append([]string(nil), c.VaryOn...), vary...)...)
+	c.mu.Lock()
+	c.varies.set(resource, vary, c.MaxEntries)
+	c.entries.set(key, entry, c.MaxEntries)
+	c.removeExpired()
+	c.mu.Unlock()
+	return entry
+}
+
+// Remove the expired responses, at most once per expiry period.
+// The caller must hold c.mu.
+func (c *ResponseCache) removeExpired() {
+	now, expiry := c.now(), c.TTL+c.StaleWhileRevalidate
+	if now.Sub(c.swept) < expiry {
+		return
+	}
+	c.swept = now
+	for element := c.entries.order.Back(); element != nil; {
+		item, prev := element.Value.(*lruItem), element.Prev()
+		if now.Sub(item.value.(*cachedResponse).stored) >= expiry {
+			c.entries.delete(item.key)
+		}
+		element = prev
+	}
+}
+
+func isCacheable(entry *cachedResponse) bool {
+	if entry.status != http.StatusOK || entry.header.Get("Set-Cookie") != "" {
+		return false
+	}
+	for _, vary := range splitHeaderList(entry.header["Vary"]) {
+		if vary == "*" {
+			return false
+		}
+	}
+	for _, directive := range splitHeaderList(entry.header["Cache-Control"]) {
+		if name := strings.ToLower(strings.SplitN(directive, "=", 2)[0]); name == "private" || name == "no-store" {
+			return false
+		}
+	}
+	return true
+}
+
+// Write the cached response, with a 110 Warning if it is stale.
+func (c *ResponseCache) replay(resp *Response, entry *cachedResponse, stale bool) {
+	header := resp.Out.Header()
+	for name, values := range entry.header {
+		header[name] = append([]string(nil), values...)
+	}
+	if age := c.now().Sub(entry.stored); age >= time.Second {
+		header.Set("Age", strconv.FormatInt(int64(age/time.Second), 10))
+	}
+	if stale {
+		addWarning(header, 110, warningAgent, "Response is Stale")
+	}
+	resp.Status = entry.status
+	resp.WriteHeader(entry.status, entry.header.Get("Content-Type"))
+	resp.Out.Write(entry.body)
+}
+
+// A ResponseWriter that keeps the response in memory.
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) Header() http.Header { return w.header }
+
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+// A map that may be limited to a number of keys, evicting the least recently
+// used beyond that.  It is not safe for concurrent use.
+type lruMap struct {
+	items map[string]*list.Element
+	order *list.List // Of *lruItem, the most recently used first.
+}
+
+type lruItem struct {
+	key   string
+	value interface{}
+}
+
+func newLRUMap() *lruMap {
+	return &lruMap{make(map[string]*list.Element), list.New()}
+}
+
+// Return the value of the key, marking it as the most recently used.
+func (m *lruMap) get(key string) (interface{}, bool) {
+	element, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+	m.order.MoveToFront(element)
+	return element.Value.(*lruItem).value, true
+}
+
+// Set the value of the key, then evict the least recently used keys while there
+// are more than max (unless max is 0).
+func (m *lruMap) set(key string, value interface{}, max int) {
+	if element, ok := m.items[key]; ok {
+		element.Value.(*lruItem).value = value
+		m.order.MoveToFront(element)
+	} else {
+		m.items[key] = m.order.PushFront(&lruItem{key, value})
+	}
+	for max > 0 && m.order.Len() > max {
+		m.delete(m.order.Back().Value.(*lruItem).key)
+	}
+}
+
+func (m *lruMap) delete(key string) {
+	if element, ok := m.items[key]; ok {
+		m.order.Remove(element)
+		delete(m.items, key)
+	}
+}
+
+func (m *lruMap) len() int {
+	return m.order.Len()
+}
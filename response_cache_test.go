@@ -0,0 +1,134 @@
+package revel
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheKey(t *testing.T) {
+	key := func(url, encoding string, varyOn ...string) string {
+		httpRequest, _ := http.NewRequest("GET", url, nil)
+		httpRequest.Header.Set("Accept-Encoding", encoding)
+		return NewRequest(httpRequest).CacheKey(varyOn...)
+	}
+
+	if key("/a?x=1&y=2", "gzip") != key("/a?y=2&x=1", "br") {
+		t.Error("Expected the query order and unvaried headers not to matter")
+	}
+	if key("/a?x=1", "gzip", "accept-encoding") == key("/a?x=1", "br", "Accept-Encoding") {
+		t.Error("Expected the varied header to change the key")
+	}
+	if key("/a?x=1", "gzip") == key("/b?x=1", "gzip") {
+		t.Error("Expected the path to change the key")
+	}
+
+	httpRequest, _ := http.NewRequest("GET", "/a", nil)
+	req := NewRequest(httpRequest)
+	htmlKey := req.CacheKey()
+	req.Format = "json"
+	if req.CacheKey() == htmlKey {
+		t.Error("Expected the format to change the key")
+	}
+}
+
+func TestResponseCache(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewResponseCache(time.Minute, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	var renders int32
+	handler := cache.Wrap(func(req *Request, resp *Response) {
+		n := atomic.AddInt32(&renders, 1)
+		resp.AddVary("Accept-Language")
+		resp.WriteHeader(http.StatusOK, "text/plain")
+		fmt.Fprintf(resp.Out, "%s %d", req.Header.Get("Accept-Language"), n)
+	})
+	var warning string
+	get := func(language string) string {
+		httpRequest, _ := http.NewRequest("GET", "/", nil)
+		httpRequest.Header.Set("Accept-Language", language)
+		recorder := httptest.NewRecorder()
+		handler(NewRequest(httpRequest), NewResponse(recorder))
+		warning = recorder.Header().Get("Warning")
+		return recorder.Body.String()
+	}
+
+	for _, expected := range []string{"en 1", "en 1", "nl 2", "en 1", "nl 2"} {
+		if actual := get(expected[:2]); actual != expected {
+			t.Errorf("Expected %q, got %q", expected, actual)
+		}
+	}
+
+	// Stale: served while refreshed in the background.
+	now = now.Add(90 * time.Second)
+	if actual := get("en"); actual != "en 1" {
+		t.Errorf("Expected the stale response, got %q", actual)
+	}
+	if warning != `110 revel "Response is Stale"` {
+		t.Errorf("Expected a 110 Warning on the stale response, got %q", warning)
+	}
+	for refreshing := true; refreshing; time.Sleep(time.Millisecond) {
+		cache.mu.Lock()
+		refreshing = len(cache.refreshing) > 0
+		cache.mu.Unlock()
+	}
+	if actual := get("en"); actual != "en 3" {
+		t.Errorf("Expected the refreshed response, got %q", actual)
+	}
+	if warning != "" {
+		t.Errorf("Expected no Warning on the refreshed response, got %q", warning)
+	}
+
+	// Expired.
+	now = now.Add(5 * time.Minute)
+	if actual := get("nl"); actual != "nl 4" {
+		t.Errorf("Expected a new response, got %q", actual)
+	}
+}
+
+func TestResponseCacheLimits(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewResponseCache(time.Minute, 0)
+	cache.MaxEntries = 2
+	cache.now = func() time.Time { return now }
+
+	renders := map[string]int{}
+	handler := cache.Wrap(func(req *Request, resp *Response) {
+		renders[req.URL.Path]++
+		resp.AddVary("Accept-Language")
+		resp.WriteHeader(http.StatusOK, "text/plain")
+		fmt.Fprintf(resp.Out, "%s %d", req.URL.Path, renders[req.URL.Path])
+	})
+	get := func(path string) string {
+		httpRequest, _ := http.NewRequest("GET", path, nil)
+		recorder := httptest.NewRecorder()
+		handler(NewRequest(httpRequest), NewResponse(recorder))
+		return recorder.Body.String()
+	}
+
+	// /b is the least recently used when /c is stored, so it is evicted.
+	for _, path := range []string{"/a", "/b", "/a", "/c"} {
+		get(path)
+	}
+	for _, expected := range []string{"/a 1", "/c 1", "/b 2"} {
+		if actual := get(expected[:2]); actual != expected {
+			t.Errorf("Expected %q, got %q", expected, actual)
+		}
+	}
+	if cache.entries.len() != 2 || cache.varies.len() != 2 {
+		t.Errorf("Expected 2 entries and varies, got %d and %d", cache.entries.len(), cache.varies.len())
+	}
+
+	// Expired responses are removed when another is stored, without being
+	// requested again.
+	now = now.Add(2 * time.Minute)
+	cache.MaxEntries = 0
+	get("/d")
+	if cache.entries.len() != 1 {
+		t.Errorf("Expected the expired entries to be removed, got %d entries", cache.entries.len())
+	}
+}
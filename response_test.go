@@ -0,0 +1,169 @@
+package revel
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestRequest(format string) *Request {
+	httpRequest, _ := http.NewRequest("GET", "/", nil)
+	req := NewRequest(httpRequest)
+	req.Format = format
+	return req
+}
+
+type teapotError struct{}
+
+func (e teapotError) Error() string   { return "short and stout" }
+func (e teapotError) HttpStatus() int { return http.StatusTeapot }
+
+func TestWriteError(t *testing.T) {
+	errConflict := errors.New("conflict")
+	RegisterErrorStatus(errConflict, http.StatusConflict)
+	defer func() { errorStatuses = errorStatuses[:len(errorStatuses)-1] }()
+
+	testCases := []struct {
+		err      error
+		status   int
+		contains string
+	}{
+		{ErrNotFound, http.StatusNotFound, "not found"},
+		{fmt.Errorf("user 5: %w", ErrForbidden), http.StatusForbidden, "user 5"},
+		{fmt.Errorf("saving: %w", errConflict), http.StatusConflict, "saving"},
+		{teapotError{}, http.StatusTeapot, "stout"},
+		{errors.New("db password is hunter2"), http.StatusInternalServerError, "Internal Server Error"},
+	}
+	for _, testCase := range testCases {
+		resp := httptest.NewRecorder()
+		NewResponse(resp).WriteError(newTestRequest("json"), testCase.err)
+		if resp.Code != testCase.status {
+			t.Errorf("%v: expected status %d, got %d", testCase.err, testCase.status, resp.Code)
+		}
+		if !strings.Contains(resp.Body.String(), testCase.contains) {
+			t.Errorf("%v: expected body to contain %q, got %s", testCase.err, testCase.contains, resp.Body)
+		}
+		if strings.Contains(resp.Body.String(), "hunter2") {
+			t.Errorf("Error detail leaked outside of dev mode: %s", resp.Body)
+		}
+		if contentType := resp.Header().Get("Content-Type"); contentType != "application/json" {
+			t.Errorf("%v: expected json content type, got %s", testCase.err, contentType)
+		}
+	}
+}
+
+func TestRecoverAndRender(t *testing.T) {
+	panicky := func(resp *Response, req *Request, partial bool) {
+		defer resp.RecoverAndRender(req)
+		if partial {
+			resp.WriteHeader(http.StatusOK, "text/plain")
+			resp.Out.Write([]byte("partial"))
+		}
+		panic("secret panic")
+	}
+
+	recorder := httptest.NewRecorder()
+	panicky(NewResponse(recorder), newTestRequest("txt"), false)
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("Expected a 500, got %d", recorder.Code)
+	}
+	if strings.Contains(recorder.Body.String(), "secret") {
+		t.Errorf("Panic value leaked outside of dev mode: %s", recorder.Body)
+	}
+
+	recorder = httptest.NewRecorder()
+	panicky(NewResponse(recorder), newTestRequest("txt"), true)
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "partial" {
+		t.Errorf("Expected the partial response to be left alone, got %d: %s", recorder.Code, recorder.Body)
+	}
+}
+
+func TestUnsupportedMediaType(t *testing.T) {
+	err := fmt.Errorf("decoding: %w", &UnsupportedMediaTypeError{"text/csv", []string{"application/json", "application/xml"}})
+	if !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Error("Expected the error to match ErrUnsupportedMediaType")
+	}
+
+	recorder := httptest.NewRecorder()
+	NewResponse(recorder).WriteError(newTestRequest("json"), err)
+	if recorder.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected a 415, got %d", recorder.Code)
+	}
+	if accept := recorder.Header().Get("Accept-Post"); accept != "application/json, application/xml" {
+		t.Errorf("Unexpected Accept-Post header: %q", accept)
+	}
+}
+
+func TestNotAcceptable(t *testing.T) {
+	testCases := []struct {
+		accept, contentType, body string
+	}{
+		{"image/png, application/json;q=0.1", "application/problem+json", `"available":["image/webp","image/avif"]`},
+		{"image/png, application/xml;q=0.1", "application/problem+xml", "<available>image/webp</available>"},
+		{"image/png", "text/plain; charset=utf-8", "\n  image/webp\n  image/avif\n"},
+	}
+	for _, testCase := range testCases {
+		recorder := httptest.NewRecorder()
+		NewResponse(recorder).NotAcceptable(NewRequest(acceptRequest(testCase.accept)), []string{"image/webp", "image/avif"})
+		if recorder.Code != http.StatusNotAcceptable {
+			t.Errorf("%s: expected status 406, got %d", testCase.accept, recorder.Code)
+		}
+		if actual := recorder.Header().Get("Content-Type"); actual != testCase.contentType {
+			t.Errorf("%s: expected content type %s, got %s", testCase.accept, testCase.contentType, actual)
+		}
+		if !strings.Contains(recorder.Body.String(), testCase.body) {
+			t.Errorf("%s: expected the body to contain %q, got %q", testCase.accept, testCase.body, recorder.Body)
+		}
+	}
+}
+
+func TestResolveFormatStrict(t *testing.T) {
+	testCases := map[string]bool{
+		"":                       true,
+		"*/*":                    true,
+		"application/json":       true,
+		"image/png":              false,
+		"text/html;q=0, */*;q=0": false,
+	}
+	for accept, expected := range testCases {
+		if _, ok := ResolveFormatStrict(acceptRequest(accept)); ok != expected {
+			t.Errorf("%q: expected ok=%v", accept, expected)
+		}
+	}
+
+	RequireAccept = true
+	defer func() { RequireAccept = false }()
+	if _, ok := ResolveFormatStrict(acceptRequest("")); ok {
+		t.Errorf("Expected a request without Accept to be rejected with RequireAccept")
+	}
+	if format, ok := ResolveFormatStrict(acceptRequest("application/json")); !ok || format != "json" {
+		t.Errorf("Expected json with RequireAccept, got %q (%v)", format, ok)
+	}
+	httpRequest, _ := http.NewRequest("GET", "/?format=xml", nil)
+	if format, ok := ResolveFormatStrict(httpRequest); !ok || format != "xml" {
+		t.Errorf("Expected the format parameter to satisfy RequireAccept, got %q (%v)", format, ok)
+	}
+}
+
+func TestBinaryResultAcceptRanges(t *testing.T) {
+	testCases := map[string]struct {
+		reader   io.Reader
+		expected string
+	}{
+		"seeker":     {strings.NewReader("hello"), "bytes"},
+		"non-seeker": {ioutil.NopCloser(strings.NewReader("hello")), "none"},
+	}
+	for name, testCase := range testCases {
+		recorder := httptest.NewRecorder()
+		result := &BinaryResult{Reader: testCase.reader, Name: "hello", Length: -1, Delivery: Inline}
+		result.Apply(newTestRequest("html"), NewResponse(recorder))
+		if actual := recorder.Header().Get("Accept-Ranges"); actual != testCase.expected {
+			t.Errorf("%s: expected Accept-Ranges: %s, got %q", name, testCase.expected, actual)
+		}
+	}
+}
@@ -251,9 +251,12 @@ func (r *BinaryResult) Apply(req *Request, resp *Response) {
 
 	// If we have a ReadSeeker, delegate to http.ServeContent
 	if rs, ok := r.Reader.(io.ReadSeeker); ok {
+		resp.SetAcceptRanges(true)
+		limitRanges(req, rs)
 		http.ServeContent(resp.Out, req.Request, r.Name, r.ModTime, rs)
 	} else {
 		// Else, do a simple io.Copy.
+		resp.SetAcceptRanges(false)
 		if r.Length != -1 {
 			resp.Out.Header().Set("Content-Length", strconv.FormatInt(r.Length, 10))
 		}
@@ -267,6 +270,16 @@ func (r *BinaryResult) Apply(req *Request, resp *Response) {
 	}
 }
 
+// Advertise whether the response supports range requests, with the
+// Accept-Ranges header ("bytes" or "none").
+func (resp *Response) SetAcceptRanges(supported bool) {
+	if supported {
+		resp.Out.Header().Set("Accept-Ranges", "bytes")
+	} else {
+		resp.Out.Header().Set("Accept-Ranges", "none")
+	}
+}
+
 type RedirectToUrlResult struct {
 	url string
 }
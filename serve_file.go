@@ -0,0 +1,37 @@
+package revel
+
+import (
+	"io"
+	"strconv"
+	"time"
+)
+
+// Serve a file, displayed inline by browsers but downloaded as an attachment by
+// other clients, with its content type taken from the file extension.  Range
+// and conditional requests are supported (see BinaryResult), the latter by
+// modtime if it is not zero.
+//
+// The disposition is chosen by, in order:
+//   - the "download" query parameter, if it is a boolean ("?download=1" for an
+//     attachment, "?download=0" for inline);
+//   - the Sec-Fetch-Dest header, for browsers that send it: inline when the
+//     file is to be shown in a document, frame, embed or object;
+//   - the request format: inline for html (i.e. browsers), and an attachment
+//     for anything else (e.g. API clients asking for JSON).
+func (resp *Response) ServeFileNegotiated(req *Request, filename string, modtime time.Time, content io.ReadSeeker) {
+	delivery := Attachment
+	if download, err := strconv.ParseBool(req.URL.Query().Get("download")); err == nil {
+		if !download {
+			delivery = Inline
+		}
+	} else if dest := req.FetchDest(); dest != "" && dest != "empty" {
+		if dest == "document" || dest == "iframe" || dest == "embed" || dest == "object" {
+			delivery = Inline
+		}
+	} else if req.Format == "html" {
+		delivery = Inline
+	}
+
+	resp.Out.Header().Set("Content-Type", ContentTypeByFilename(filename))
+	(&BinaryResult{Reader: content, Name: filename, Delivery: delivery, Length: -1, ModTime: modtime}).Apply(req, resp)
+}
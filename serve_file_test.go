@@ -0,0 +1,56 @@
+package revel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServeFileNegotiated(t *testing.T) {
+	testCases := []struct {
+		url, accept, fetchDest, disposition, filename, contentType string
+	}{
+		{"/report", "text/html", "", "inline", "report", DefaultFileContentType},
+		{"/report", "application/json", "", "attachment", "report", DefaultFileContentType},
+		{"/report?download=1", "text/html", "document", "attachment", "report", DefaultFileContentType},
+		{"/report?download=false", "application/json", "", "inline", "report", DefaultFileContentType},
+		{"/report", "application/json", "iframe", "inline", "report", DefaultFileContentType},
+		{"/report", "text/html", "empty", "inline", "report", DefaultFileContentType},
+		{"/report", "*/*", "image", "attachment", "report", DefaultFileContentType},
+		{"/report.csv", "text/html", "", "inline", "report.csv", "text/csv; charset=utf-8"},
+	}
+	srcPath, _ := findSrcPaths(REVEL_IMPORT_PATH)
+	defer func(confPaths []string) { ConfPaths = confPaths }(ConfPaths)
+	ConfPaths = []string{path.Join(srcPath, filepath.FromSlash(REVEL_IMPORT_PATH), "conf")}
+	LoadMimeConfig()
+
+	modtime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, testCase := range testCases {
+		httpRequest, _ := http.NewRequest("GET", testCase.url, nil)
+		httpRequest.Header.Set("Accept", testCase.accept)
+		httpRequest.Header.Set("Range", "bytes=0-4")
+		if testCase.fetchDest != "" {
+			httpRequest.Header.Set("Sec-Fetch-Dest", testCase.fetchDest)
+		}
+		recorder := httptest.NewRecorder()
+		NewResponse(recorder).ServeFileNegotiated(NewRequest(httpRequest), testCase.filename, modtime, strings.NewReader("hello world"))
+
+		header := recorder.Header()
+		if disposition := header.Get("Content-Disposition"); !strings.HasPrefix(disposition, testCase.disposition+";") {
+			t.Errorf("%+v: unexpected Content-Disposition %q", testCase, disposition)
+		}
+		if recorder.Code != http.StatusPartialContent || recorder.Body.String() != "hello" {
+			t.Errorf("%+v: expected the range to be served, got %d %q", testCase, recorder.Code, recorder.Body)
+		}
+		if lastModified := header.Get("Last-Modified"); lastModified != modtime.Format(http.TimeFormat) {
+			t.Errorf("%+v: unexpected Last-Modified %q", testCase, lastModified)
+		}
+		if contentType := header.Get("Content-Type"); contentType != testCase.contentType {
+			t.Errorf("%+v: unexpected Content-Type %q", testCase, contentType)
+		}
+	}
+}
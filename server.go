@@ -35,6 +35,10 @@ func handle(w http.ResponseWriter, r *http.Request) {
 func handleInternal(w http.ResponseWriter, r *http.Request, ws *websocket.Conn) {
 	// TODO: StaticPathsCache
 	req, resp := NewRequest(r), NewResponse(w)
+	if CompressResponses && ws == nil {
+		resp.Compress(req)
+		defer resp.Close()
+	}
 
 	if MainWatcher != nil {
 		err := MainWatcher.Notify()
@@ -44,6 +48,28 @@ func handleInternal(w http.ResponseWriter, r *http.Request, ws *websocket.Conn)
 		}
 	}
 
+	if req.IsMisdirected() {
+		resp.MisdirectedRequest(req)
+		return
+	}
+
+	if req.InMaintenance() {
+		resp.ServiceUnavailable(req, MaintenanceRetryAfter)
+		return
+	}
+
+	if req.IsServerOptions() {
+		resp.Options(ServerMethods)
+		return
+	}
+
+	if RequireAccept {
+		if _, ok := ResolveFormatStrict(r); !ok {
+			resp.NotAcceptable(req, nil)
+			return
+		}
+	}
+
 	// Figure out the Controller/Action
 	var route *RouteMatch = MainRouter.Route(r)
 	if route == nil {
@@ -73,6 +99,7 @@ func handleInternal(w http.ResponseWriter, r *http.Request, ws *websocket.Conn)
 	}
 
 	// Add the route Params to the Request Params.
+	req.routeParams = route.Params
 	for key, value := range route.Params {
 		url.Values(controller.Params.Values).Add(key, value)
 	}
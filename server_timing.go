@@ -0,0 +1,56 @@
+package revel
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Add a metric to the Server-Timing header, for display in browser developer
+// tools.  e.g. AddServerTiming("db", 53*time.Millisecond, "Database") adds
+//
+//     db;dur=53;desc="Database"
+//
+// The duration is reported in milliseconds, and is omitted if it is zero, as is
+// an empty description.  Each call adds another metric.
+func (resp *Response) AddServerTiming(name string, dur time.Duration, desc string) {
+	metric := name
+	if dur != 0 {
+		ms := float64(dur) / float64(time.Millisecond)
+		metric += ";dur=" + strconv.FormatFloat(ms, 'f', -1, 64)
+	}
+	if desc != "" {
+		metric += ";desc=" + quoteHeaderString(desc)
+	}
+	resp.Out.Header().Add("Server-Timing", metric)
+}
+
+// Set the Timing-Allow-Origin header, allowing the given origins to read the
+// detailed Resource Timing of the response.  If any of them is "*", every origin
+// is allowed.  With no origins, the header follows the Access-Control-Allow-Origin
+// already set on the response, so that the origins allowed by CORS may also read
+// the timing; it is not set if there is none.
+//
+//     resp.Out.Header().Set("Access-Control-Allow-Origin", "https://example.com")
+//     resp.SetTimingAllowOrigin() // Timing-Allow-Origin: https://example.com
+func (resp *Response) SetTimingAllowOrigin(origins ...string) {
+	header := resp.Out.Header()
+	if len(origins) == 0 {
+		origins = []string{header.Get("Access-Control-Allow-Origin")}
+	}
+	allowed := make([]string, 0, len(origins))
+	for _, origin := range origins {
+		origin = strings.TrimSpace(origin)
+		if origin == "*" {
+			allowed = []string{"*"}
+			break
+		}
+		if origin != "" && !ContainsString(allowed, origin) {
+			allowed = append(allowed, origin)
+		}
+	}
+	if len(allowed) == 0 {
+		return
+	}
+	header.Set("Timing-Allow-Origin", strings.Join(allowed, ", "))
+}
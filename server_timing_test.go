@@ -0,0 +1,49 @@
+package revel
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAddServerTiming(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	resp := NewResponse(recorder)
+	resp.AddServerTiming("db", 53*time.Millisecond, "Database")
+	resp.AddServerTiming("render", 1500*time.Microsecond, `The "view"`)
+	resp.AddServerTiming("miss", 0, "")
+
+	expected := []string{`db;dur=53;desc="Database"`, `render;dur=1.5;desc="The \"view\""`, "miss"}
+	if actual := recorder.Header()["Server-Timing"]; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestSetTimingAllowOrigin(t *testing.T) {
+	tests := []struct {
+		allowOrigin string
+		origins     []string
+		expected    []string
+	}{
+		{"", []string{"*"}, []string{"*"}},
+		{"", []string{"https://a.example", " https://b.example ", "", "https://a.example"}, []string{"https://a.example, https://b.example"}},
+		{"", []string{"https://a.example", "*"}, []string{"*"}},
+		{"https://cors.example", nil, []string{"https://cors.example"}},
+		{"*", nil, []string{"*"}},
+		{"https://cors.example", []string{"https://a.example"}, []string{"https://a.example"}},
+		{"", nil, nil},
+		{"", []string{" "}, nil},
+	}
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		resp := NewResponse(recorder)
+		if test.allowOrigin != "" {
+			recorder.Header().Set("Access-Control-Allow-Origin", test.allowOrigin)
+		}
+		resp.SetTimingAllowOrigin(test.origins...)
+		if actual := recorder.Header()["Timing-Allow-Origin"]; !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%q with %q: Expected %q, got %q", test.origins, test.allowOrigin, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package revel
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var ErrStreamingUnsupported = errors.New("response writer does not support streaming")
+
+// Streams server-sent events (SSE) to the response.
+type SSEWriter struct {
+	req *Request
+	out http.ResponseWriter
+}
+
+// Start a server-sent events (text/event-stream) response, and return a writer
+// for its events.
+//
+// The response is neither cached (Cache-Control: no-cache) nor compressed, and
+// proxies are asked not to buffer it.  An error is returned if the response has
+// already been started, or if the writer can not flush.
+func (resp *Response) SSE(req *Request) (*SSEWriter, error) {
+	if resp.wroteHeader {
+		return nil, ErrHeaderWritten
+	}
+	if _, ok := resp.Unwrap().(http.Flusher); !ok {
+		return nil, ErrStreamingUnsupported
+	}
+	header := resp.Out.Header()
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no")
+	if req.ProtoMajor == 1 {
+		header.Set("Connection", "keep-alive")
+	}
+	header.Del("Content-Length")
+	resp.Status, resp.ContentType = http.StatusOK, "text/event-stream"
+	resp.WriteHeader(http.StatusOK, "text/event-stream")
+
+	w := &SSEWriter{req, resp.Out}
+	return w, w.flush()
+}
+
+// Send an event with the given type (or a plain message, if event is empty)
+// and data, and flush it to the client.  Multi-line data is sent as one data
+// field per line.  An error is returned if the client has gone away, in which
+// case the producer should stop.
+func (w *SSEWriter) Send(event, data string) error {
+	var b strings.Builder
+	if event != "" {
+		b.WriteString("event: " + strings.NewReplacer("\r", "", "\n", "").Replace(event) + "\n")
+	}
+	for _, line := range strings.Split(strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(data), "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+	return w.write(b.String())
+}
+
+// Tell the client how long to wait, in milliseconds, before reconnecting if
+// the stream is closed.
+func (w *SSEWriter) SendRetry(ms int) error {
+	return w.write("retry: " + strconv.Itoa(ms) + "\n\n")
+}
+
+func (w *SSEWriter) write(s string) error {
+	if err := w.req.Context().Err(); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w.out, s); err != nil {
+		return err
+	}
+	return w.flush()
+}
+
+func (w *SSEWriter) flush() error {
+	if err := w.req.Context().Err(); err != nil {
+		return err
+	}
+	if flusher, ok := w.out.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
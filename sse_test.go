@@ -0,0 +1,55 @@
+package revel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSSE(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/events", nil)
+	httpRequest.Header.Set("Accept-Encoding", "gzip")
+	req := NewRequest(httpRequest)
+	recorder := httptest.NewRecorder()
+	resp := NewResponse(recorder)
+	resp.Compress(req)
+
+	w, err := resp.SSE(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SendRetry(3000)
+	w.Send("", "hello")
+	w.Send("update", "line 1\nline 2\r\nline 3")
+	resp.Close()
+
+	expected := "retry: 3000\n\ndata: hello\n\nevent: update\ndata: line 1\ndata: line 2\ndata: line 3\n\n"
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Expected %q, got %q", expected, body)
+	}
+	for name, value := range map[string]string{
+		"Content-Type":     "text/event-stream",
+		"Cache-Control":    "no-cache",
+		"Connection":       "keep-alive",
+		"Content-Encoding": "",
+	} {
+		if actual := recorder.Header().Get(name); actual != value {
+			t.Errorf("Expected %s %q, got %q", name, value, actual)
+		}
+	}
+	if !recorder.Flushed {
+		t.Errorf("Expected the events to be flushed")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req.Request = req.Request.WithContext(ctx)
+	if err := w.Send("update", "gone"); err != context.Canceled {
+		t.Errorf("Expected the disconnect to be reported, got %v", err)
+	}
+
+	if _, err := resp.SSE(req); err != ErrHeaderWritten {
+		t.Errorf("Expected ErrHeaderWritten, got %v", err)
+	}
+}
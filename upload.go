@@ -0,0 +1,80 @@
+package revel
+
+import (
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+// The maximum size of a request body, in bytes, that StreamUpload will read.
+// Configured with http.maxuploadsize in app.conf.
+var MaxUploadSize int64 = 1 << 30 // 1 GB
+
+func init() {
+	OnAppStart(func() {
+		MaxUploadSize = int64(Config.IntDefault("http.maxuploadsize", int(MaxUploadSize)))
+	})
+}
+
+// Stream the file posted in the named field of a multipart request to dst,
+// without buffering it in memory or on disk, and return the number of bytes
+// written and the file's header (with its Size filled in).
+//
+// The other (non-file) fields encountered in the body are returned too.  Their
+// values are limited to MaxBodySize, and other files are discarded.  The whole
+// body is limited to MaxUploadSize: ErrBodyTooLarge is returned beyond that,
+// and ErrTooManyParts beyond MaxMultipartParts parts.
+// If the field is not found, http.ErrMissingFile is returned.  Since the body
+// is consumed, the request's form values may not be parsed afterwards.
+func (r *Request) StreamUpload(fieldName string, dst io.Writer) (written int64, header *multipart.FileHeader, fields url.Values, err error) {
+	if err := r.limitMultipart(MaxUploadSize); err != nil {
+		return 0, nil, nil, err
+	}
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	fields = make(url.Values)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return written, header, fields, multipartError(err)
+		}
+
+		name := part.FormName()
+		switch {
+		case part.FileName() == "":
+			value, err := ioutil.ReadAll(io.LimitReader(part, MaxBodySize+1))
+			if err != nil {
+				return written, header, fields, multipartError(err)
+			}
+			if int64(len(value)) > MaxBodySize {
+				return written, header, fields, ErrBodyTooLarge
+			}
+			fields.Add(name, string(value))
+		case name == fieldName && header == nil:
+			header = &multipart.FileHeader{Filename: part.FileName(), Header: part.Header}
+			written, err = io.Copy(dst, part)
+			header.Size = written
+			if err != nil {
+				return written, header, fields, multipartError(err)
+			}
+		default:
+			if _, err := io.Copy(ioutil.Discard, part); err != nil {
+				return written, header, fields, multipartError(err)
+			}
+		}
+		part.Close()
+	}
+
+	if header == nil {
+		return 0, nil, fields, http.ErrMissingFile
+	}
+	return written, header, fields, nil
+}
@@ -0,0 +1,58 @@
+package revel
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func uploadRequest() *Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	writer.WriteField("title", "Holiday")
+	other, _ := writer.CreateFormFile("thumbnail", "thumb.png")
+	other.Write([]byte("thumbnail"))
+	file, _ := writer.CreateFormFile("photo", "photo.jpg")
+	file.Write([]byte(strings.Repeat("x", 1000)))
+	writer.WriteField("album", "2024")
+	writer.Close()
+
+	httpRequest, _ := http.NewRequest("POST", "/upload", &body)
+	httpRequest.Header.Set("Content-Type", writer.FormDataContentType())
+	return NewRequest(httpRequest)
+}
+
+func TestStreamUpload(t *testing.T) {
+	var dst bytes.Buffer
+	written, header, fields, err := uploadRequest().StreamUpload("photo", &dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != 1000 || dst.Len() != 1000 || header.Filename != "photo.jpg" || header.Size != 1000 {
+		t.Errorf("Unexpected upload: %d bytes, %+v", written, header)
+	}
+	if fields.Get("title") != "Holiday" || fields.Get("album") != "2024" || len(fields) != 2 {
+		t.Errorf("Unexpected fields %v", fields)
+	}
+
+	if _, _, _, err := uploadRequest().StreamUpload("missing", &dst); err != http.ErrMissingFile {
+		t.Errorf("Expected ErrMissingFile, got %v", err)
+	}
+}
+
+func TestStreamUploadTooLarge(t *testing.T) {
+	defer func(size int64) { MaxUploadSize = size }(MaxUploadSize)
+	MaxUploadSize = 500
+
+	req := uploadRequest()
+	req.ContentLength = -1 // Unknown, so that the limit is enforced while reading.
+	var dst bytes.Buffer
+	if _, _, _, err := req.StreamUpload("photo", &dst); err != ErrBodyTooLarge {
+		t.Errorf("Expected ErrBodyTooLarge, got %v", err)
+	}
+	if _, _, _, err := uploadRequest().StreamUpload("photo", &dst); err != ErrBodyTooLarge {
+		t.Errorf("Expected ErrBodyTooLarge for the declared length, got %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package revel
+
+import (
+	"regexp"
+	"strings"
+)
+
+// The patterns matched (case-insensitively) against the User-Agent to identify
+// crawlers and other bots.  Each may be a plain substring or a regular
+// expression.  Configured with http.botagents (a comma-separated list) in
+// app.conf, which replaces the default list.
+var BotUserAgents = compileBotPatterns([]string{
+	"bot", "crawler", "spider", "slurp", "crawling",
+	"facebookexternalhit", "embedly", "quora link preview", "outbrain",
+	"pinterest", "vkshare", "w3c_validator", "whatsapp", "lighthouse",
+	`^curl/`, `^wget/`, `^python-requests/`, `^go-http-client/`,
+})
+
+func init() {
+	OnAppStart(func() {
+		if patterns := splitConfigList(Config.StringDefault("http.botagents", "")); len(patterns) > 0 {
+			BotUserAgents = compileBotPatterns(patterns)
+		}
+	})
+}
+
+// Return the User-Agent header of the request.
+func (r *Request) UserAgent() string {
+	return r.Header.Get("User-Agent")
+}
+
+// Return true if the User-Agent matches one of the BotUserAgents.
+// This is only a heuristic: a missing User-Agent is not taken to be a bot, and
+// bots are free to claim to be browsers.
+func (r *Request) IsBot() bool {
+	userAgent := r.UserAgent()
+	if userAgent == "" {
+		return false
+	}
+	for _, pattern := range BotUserAgents {
+		if pattern.MatchString(userAgent) {
+			return true
+		}
+	}
+	return false
+}
+
+// Compile the given patterns case-insensitively, skipping invalid ones.
+func compileBotPatterns(patterns []string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			ERROR.Printf("Invalid bot user agent pattern '%s': %s", pattern, err)
+			continue
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled
+}
+
+// The components of a User-Agent, as identified by Request.ParsedUserAgent.
+type UserAgentInfo struct {
+	Raw     string // The User-Agent header, as sent.
+	Browser string // e.g. "Chrome", "Firefox", "Safari", "Edge", or "Other"
+	OS      string // e.g. "Windows", "macOS", "iOS", "Android", "Linux", or "Other"
+	Device  string // "mobile", "tablet" or "desktop"
+	Bot     bool   // See Request.IsBot.
+}
+
+// A family of user agents, identified by any of the given substrings.
+type userAgentRule struct {
+	family  string
+	markers []string
+}
+
+// The browser and OS families, in the order they are tried.  Since browsers
+// claim to be others (e.g. Edge and Chrome both claim to be Safari), the more
+// specific ones come first.
+var (
+	browserRules = []userAgentRule{
+		{"Edge", []string{"Edg/", "Edge/", "EdgA/", "EdgiOS/"}},
+		{"Opera", []string{"OPR/", "Opera"}},
+		{"Samsung Internet", []string{"SamsungBrowser/"}},
+		{"Firefox", []string{"Firefox/", "FxiOS/"}},
+		{"Chrome", []string{"Chrome/", "CriOS/", "Chromium/"}},
+		{"Safari", []string{"Safari/"}},
+		{"Internet Explorer", []string{"MSIE ", "Trident/"}},
+	}
+	osRules = []userAgentRule{
+		{"Windows", []string{"Windows"}},
+		{"iOS", []string{"iPhone", "iPad", "iPod"}},
+		{"Android", []string{"Android"}},
+		{"Chrome OS", []string{"CrOS"}},
+		{"macOS", []string{"Macintosh", "Mac OS X"}},
+		{"Linux", []string{"Linux", "X11"}},
+	}
+)
+
+// Identify the browser, OS and kind of device from the User-Agent.
+//
+// This is a heuristic, using a few simple rules for the common browsers, and
+// it is easily fooled: user agents may claim anything, and some (e.g. iPads
+// in desktop mode) claim to be desktop browsers.  Prefer feature detection or
+// the Sec-CH-UA client hints where they are available.
+func (r *Request) ParsedUserAgent() UserAgentInfo {
+	userAgent := r.UserAgent()
+	info := UserAgentInfo{
+		Raw:     userAgent,
+		Browser: matchUserAgentRule(userAgent, browserRules),
+		OS:      matchUserAgentRule(userAgent, osRules),
+		Device:  "desktop",
+		Bot:     r.IsBot(),
+	}
+	switch {
+	case strings.Contains(userAgent, "iPad") || strings.Contains(userAgent, "Tablet") ||
+		(strings.Contains(userAgent, "Android") && !strings.Contains(userAgent, "Mobile")):
+		info.Device = "tablet"
+	case strings.Contains(userAgent, "Mobi") || strings.Contains(userAgent, "iPhone") ||
+		strings.Contains(userAgent, "iPod"):
+		info.Device = "mobile"
+	}
+	return info
+}
+
+func matchUserAgentRule(userAgent string, rules []userAgentRule) string {
+	for _, rule := range rules {
+		for _, marker := range rule.markers {
+			if strings.Contains(userAgent, marker) {
+				return rule.family
+			}
+		}
+	}
+	return "Other"
+}
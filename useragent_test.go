@@ -0,0 +1,57 @@
+package revel
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestIsBot(t *testing.T) {
+	testCases := map[string]bool{
+		"": false,
+		"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)":  true,
+		"Mozilla/5.0 (compatible; bingbot/2.0; +http://www.bing.com/bingbot.htm)":   true,
+		"facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)": true,
+		"curl/8.4.0": true,
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15 Version/17.0 Safari/605.1.15": false,
+		"Mozilla/5.0 (X11; Linux x86_64; rv:120.0) Gecko/20100101 Firefox/120.0 (curl/8.4.0)":            false,
+	}
+	for userAgent, expected := range testCases {
+		httpRequest, _ := http.NewRequest("GET", "/", nil)
+		httpRequest.Header.Set("User-Agent", userAgent)
+		if actual := NewRequest(httpRequest).IsBot(); actual != expected {
+			t.Errorf("%q: expected %v, got %v", userAgent, expected, actual)
+		}
+	}
+}
+
+func TestParsedUserAgent(t *testing.T) {
+	testCases := map[string]UserAgentInfo{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36": {
+			Browser: "Chrome", OS: "Windows", Device: "desktop"},
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0": {
+			Browser: "Edge", OS: "Windows", Device: "desktop"},
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Safari/605.1.15": {
+			Browser: "Safari", OS: "macOS", Device: "desktop"},
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/120.0.6099.119 Mobile/15E148 Safari/604.1": {
+			Browser: "Chrome", OS: "iOS", Device: "mobile"},
+		"Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1": {
+			Browser: "Safari", OS: "iOS", Device: "tablet"},
+		"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36": {
+			Browser: "Chrome", OS: "Android", Device: "mobile"},
+		"Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/23.0 Chrome/115.0.0.0 Safari/537.36": {
+			Browser: "Samsung Internet", OS: "Android", Device: "tablet"},
+		"Mozilla/5.0 (X11; Linux x86_64; rv:120.0) Gecko/20100101 Firefox/120.0": {
+			Browser: "Firefox", OS: "Linux", Device: "desktop"},
+		"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)": {
+			Browser: "Other", OS: "Other", Device: "desktop", Bot: true},
+		"": {Browser: "Other", OS: "Other", Device: "desktop"},
+	}
+	for userAgent, expected := range testCases {
+		httpRequest, _ := http.NewRequest("GET", "/", nil)
+		httpRequest.Header.Set("User-Agent", userAgent)
+		expected.Raw = userAgent
+		if actual := NewRequest(httpRequest).ParsedUserAgent(); actual != expected {
+			t.Errorf("%q: expected %+v, got %+v", userAgent, expected, actual)
+		}
+	}
+}
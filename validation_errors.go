@@ -0,0 +1,117 @@
+package revel
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+)
+
+// Validation failures, as the messages for each field, keyed by field name.
+type ValidationErrors map[string][]string
+
+// Add a message for the given field.
+func (e ValidationErrors) Add(field, message string) {
+	e[field] = append(e[field], message)
+}
+
+// Return the field names, sorted.
+func (e ValidationErrors) Fields() []string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Return every error in the validation context, keyed by their Key.
+func (v *Validation) ValidationErrors() ValidationErrors {
+	errs := ValidationErrors{}
+	for _, e := range v.Errors {
+		errs.Add(e.Key, e.Message)
+	}
+	return errs
+}
+
+// The body of a validation failure response, as marshaled by each format: in
+// JSON, an object of arrays of messages; in XML, a field element (with the name
+// as an attribute) of message elements for each field; and in text, a line for
+// each message.
+type validationErrorsBody struct {
+	XMLName xml.Name             `xml:"errors" json:"-"`
+	Errors  ValidationErrors     `xml:"-" json:"errors"`
+	Fields  []xmlValidationField `xml:"field" json:"-"`
+}
+
+type xmlValidationField struct {
+	Name     string   `xml:"name,attr"`
+	Messages []string `xml:"message"`
+}
+
+func newValidationErrorsBody(errs ValidationErrors) validationErrorsBody {
+	if errs == nil {
+		errs = ValidationErrors{}
+	}
+	body := validationErrorsBody{Errors: errs}
+	for _, field := range errs.Fields() {
+		body.Fields = append(body.Fields, xmlValidationField{field, errs[field]})
+	}
+	return body
+}
+
+func (b validationErrorsBody) String() string {
+	var buf bytes.Buffer
+	for _, field := range b.Errors.Fields() {
+		for _, message := range b.Errors[field] {
+			fmt.Fprintf(&buf, "%s: %s\n", field, message)
+		}
+	}
+	return buf.String()
+}
+
+// Write a 422 Unprocessable Entity response listing the validation errors, in
+// the request format, marshaled with the format's Marshal func (see
+// RegisterFormat).  In JSON, the errors are an object of arrays of messages:
+//
+//     {"errors": {"email": ["Required"], "name": ["Required", "Minimum size is 2"]}}
+//
+// In XML, a field element (with the name as an attribute) of message elements
+// for each field:
+//
+//     <errors><field name="email"><message>Required</message></field>...</errors>
+//
+// HTML is written as a list, and other formats without a Marshal func as text.
+// Fields are always sorted by name.
+func (resp *Response) ValidationFailed(req *Request, errs ValidationErrors) {
+	const status = http.StatusUnprocessableEntity
+	resp.Status = status
+	body := newValidationErrorsBody(errs)
+	if format := LookupFormat(req.Format); format != nil && format.Marshal != nil {
+		resp.writeFormat(req, format, body)
+		return
+	}
+
+	var buf bytes.Buffer
+	contentType := "text/plain"
+	if req.Format == "html" {
+		title := template.HTMLEscapeString(http.StatusText(status))
+		fmt.Fprintf(&buf, "<html><head><title>%s</title></head><body><h1>%s</h1><ul>", title, title)
+		for _, field := range body.Errors.Fields() {
+			for _, message := range body.Errors[field] {
+				fmt.Fprintf(&buf, "<li>%s: %s</li>", template.HTMLEscapeString(field), template.HTMLEscapeString(message))
+			}
+		}
+		buf.WriteString("</ul></body></html>")
+		contentType = "text/html"
+	} else {
+		buf.WriteString(body.String())
+	}
+	if resp.ContentType == "" {
+		resp.SetContentType(contentType)
+	}
+	resp.WriteHeader(status, resp.ContentType)
+	resp.Out.Write(buf.Bytes())
+}
@@ -0,0 +1,79 @@
+package revel
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidationFailed(t *testing.T) {
+	errs := ValidationErrors{}
+	errs.Add("name", "Required")
+	errs.Add("name", "Minimum size is 2")
+	errs.Add("email", "Must be <valid>")
+
+	testCases := map[string]struct{ contentType, body string }{
+		"json": {"application/json",
+			`{"errors":{"email":["Must be \u003cvalid\u003e"],"name":["Required","Minimum size is 2"]}}`},
+		"xml": {"application/xml", `<errors><field name="email"><message>Must be &lt;valid&gt;</message></field>` +
+			`<field name="name"><message>Required</message><message>Minimum size is 2</message></field></errors>`},
+		"txt": {"text/plain; charset=utf-8", "email: Must be <valid>\nname: Required\nname: Minimum size is 2\n"},
+		"html": {"text/html; charset=utf-8", "<html><head><title>Unprocessable Entity</title></head><body><h1>Unprocessable Entity</h1>" +
+			"<ul><li>email: Must be &lt;valid&gt;</li><li>name: Required</li><li>name: Minimum size is 2</li></ul></body></html>"},
+	}
+	for format, expected := range testCases {
+		recorder := httptest.NewRecorder()
+		NewResponse(recorder).ValidationFailed(newTestRequest(format), errs)
+		if recorder.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: expected status 422, got %d", format, recorder.Code)
+		}
+		if contentType := recorder.Header().Get("Content-Type"); contentType != expected.contentType {
+			t.Errorf("%s: expected Content-Type %q, got %q", format, expected.contentType, contentType)
+		}
+		if body := recorder.Body.String(); body != expected.body {
+			t.Errorf("%s: expected body %q, got %q", format, expected.body, body)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	NewResponse(recorder).ValidationFailed(newTestRequest("json"), nil)
+	if body := recorder.Body.String(); body != `{"errors":{}}` {
+		t.Errorf("Expected an empty errors object, got %q", body)
+	}
+
+	// The format's media types and Marshal func are used.
+	format := LookupFormat("json")
+	defer func(marshal func(interface{}) ([]byte, error)) { format.Marshal = marshal }(format.Marshal)
+	format.Marshal = func(v interface{}) ([]byte, error) {
+		return json.MarshalIndent(v, "", " ")
+	}
+	req := newTestRequest("json")
+	req.Header.Set("Accept", "text/javascript")
+	recorder = httptest.NewRecorder()
+	NewResponse(recorder).ValidationFailed(req, ValidationErrors{"name": {"Required"}})
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "text/javascript; charset=utf-8" {
+		t.Errorf("Expected the negotiated media type, got %q", contentType)
+	}
+	if body := recorder.Body.String(); body != "{\n \"errors\": {\n  \"name\": [\n   \"Required\"\n  ]\n }\n}" {
+		t.Errorf("Expected the body from the format's Marshal, got %q", body)
+	}
+
+	// Formats without a Marshal func are written as text.
+	recorder = httptest.NewRecorder()
+	NewResponse(recorder).ValidationFailed(newTestRequest("csv"), ValidationErrors{"name": {"Required"}})
+	if body := recorder.Body.String(); body != "name: Required\n" || recorder.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected a text body, got %d %q", recorder.Code, body)
+	}
+}
+
+func TestValidationErrorsFromValidation(t *testing.T) {
+	v := &Validation{}
+	v.Required("").Key("name")
+	v.MinSize("", 2).Key("name")
+	v.Required("x").Key("email")
+	errs := v.ValidationErrors()
+	if len(errs) != 1 || len(errs["name"]) != 2 {
+		t.Errorf("Expected two errors for name, got %v", errs)
+	}
+}
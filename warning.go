@@ -0,0 +1,31 @@
+package revel
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// The warn-agent pseudonym used for the warnings the framework adds itself.
+const warningAgent = "revel"
+
+// Add a Warning header, formatted as: code agent "text" ["date"]
+// (e.g. 110 revel "Response is stale")  An empty agent is written as "-", and
+// the date (an HTTP-date) is only included if one is given.
+//
+// The Warning header is obsolete (RFC 9111), but still honored by many clients
+// and intermediaries.
+func (resp *Response) AddWarning(code int, agent, text string, date ...time.Time) {
+	addWarning(resp.Out.Header(), code, agent, text, date...)
+}
+
+func addWarning(header http.Header, code int, agent, text string, date ...time.Time) {
+	if agent == "" {
+		agent = "-"
+	}
+	value := strconv.Itoa(code) + " " + agent + " " + quoteHeaderString(text)
+	if len(date) > 0 && !date[0].IsZero() {
+		value += " " + quoteHeaderString(date[0].UTC().Format(http.TimeFormat))
+	}
+	header.Add("Warning", value)
+}
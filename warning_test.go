@@ -0,0 +1,25 @@
+package revel
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAddWarning(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	resp := NewResponse(recorder)
+	resp.AddWarning(110, "cache.example.com:8080", "Response is Stale")
+	resp.AddWarning(199, "", `Say "hi"`, time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60)))
+	resp.AddWarning(299, "-", "Persistent", time.Time{})
+
+	expected := []string{
+		`110 cache.example.com:8080 "Response is Stale"`,
+		`199 - "Say \"hi\"" "Wed, 01 May 2024 10:00:00 GMT"`,
+		`299 - "Persistent"`,
+	}
+	if actual := recorder.Header()["Warning"]; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
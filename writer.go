@@ -0,0 +1,158 @@
+package revel
+
+import (
+	"io"
+	"net/http"
+)
+
+// Wrap Response.Out with the writer returned by wrap, for middleware that
+// filters the response (e.g. to compress or rewrite it).  Writers may be
+// wrapped any number of times; the last one wrapped is written to first.
+//
+// Flushing, hijacking and pushing are passed to the wrapper if it implements
+// them, or else to the writer it wraps, so that Response.Out keeps supporting
+// them where either does (and only then).  Response.Close closes each wrapper
+// that is an io.Closer.
+func (resp *Response) WrapWriter(wrap func(http.ResponseWriter) http.ResponseWriter) {
+	wrapper, inner := wrap(resp.Out), resp.Out
+	flusher, ok := wrapper.(http.Flusher)
+	if !ok {
+		flusher, _ = inner.(http.Flusher)
+	}
+	hijacker, ok := wrapper.(http.Hijacker)
+	if !ok {
+		hijacker, _ = inner.(http.Hijacker)
+	}
+	pusher, ok := wrapper.(http.Pusher)
+	if !ok {
+		pusher, _ = inner.(http.Pusher)
+	}
+	resp.Out = withOptionalInterfaces(&wrappedWriter{wrapper, inner}, flusher, hijacker, pusher)
+}
+
+// Return the ResponseWriter that the Response was created with, beneath any
+// wrappers.  e.g. to type-assert it for server-specific interfaces
+func (resp *Response) Unwrap() http.ResponseWriter {
+	return resp.original
+}
+
+// Finish writing the response, closing each wrapper around the original
+// writer (e.g. to flush a compressed body).  A writer assigned to Response.Out
+// directly, rather than with WrapWriter, is closed too if it is an io.Closer.
+func (resp *Response) Close() error {
+	var err error
+	for w := resp.Out; w != nil; {
+		if optional, ok := w.(interface{ base() optionalWriter }); ok {
+			w = optional.base()
+		}
+		wrapped, ok := w.(*wrappedWriter)
+		if !ok {
+			if closer, ok := w.(io.Closer); ok {
+				if closeErr := closer.Close(); err == nil {
+					err = closeErr
+				}
+			}
+			break
+		}
+		if closer, ok := wrapped.ResponseWriter.(io.Closer); ok {
+			if closeErr := closer.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		w = wrapped.inner
+	}
+	return err
+}
+
+// A wrapper around a ResponseWriter, with the writer it wraps.
+type wrappedWriter struct {
+	http.ResponseWriter
+	inner http.ResponseWriter
+}
+
+// Return the wrapped writer, for http.ResponseController.
+func (w *wrappedWriter) Unwrap() http.ResponseWriter {
+	return w.inner
+}
+
+// Copy the body to the wrapper, with its ReadFrom if it has one.
+func (w *wrappedWriter) ReadFrom(src io.Reader) (int64, error) {
+	return io.Copy(w.ResponseWriter, src)
+}
+
+// The writers that Response.Out may be, which are given the optional
+// interfaces of the writers beneath them.
+type optionalWriter interface {
+	http.ResponseWriter
+	io.ReaderFrom
+	Unwrap() http.ResponseWriter
+}
+
+type optionalBase struct {
+	optionalWriter
+}
+
+func (b optionalBase) base() optionalWriter {
+	return b.optionalWriter
+}
+
+// The writers that are also Flushers, Hijackers and Pushers, for each
+// combination of these, so that type-asserting Response.Out finds only what
+// will work.
+type (
+	flushWriter struct {
+		optionalBase
+		http.Flusher
+	}
+	hijackWriter struct {
+		optionalBase
+		http.Hijacker
+	}
+	pushWriter struct {
+		optionalBase
+		http.Pusher
+	}
+	flushHijackWriter struct {
+		optionalBase
+		http.Flusher
+		http.Hijacker
+	}
+	flushPushWriter struct {
+		optionalBase
+		http.Flusher
+		http.Pusher
+	}
+	hijackPushWriter struct {
+		optionalBase
+		http.Hijacker
+		http.Pusher
+	}
+	flushHijackPushWriter struct {
+		optionalBase
+		http.Flusher
+		http.Hijacker
+		http.Pusher
+	}
+)
+
+// Return w, as a Flusher, Hijacker and Pusher for those that are not nil.
+func withOptionalInterfaces(w optionalWriter, flusher http.Flusher, hijacker http.Hijacker, pusher http.Pusher) http.ResponseWriter {
+	base := optionalBase{w}
+	switch {
+	case flusher != nil && hijacker != nil && pusher != nil:
+		return flushHijackPushWriter{base, flusher, hijacker, pusher}
+	case flusher != nil && hijacker != nil:
+		return flushHijackWriter{base, flusher, hijacker}
+	case flusher != nil && pusher != nil:
+		return flushPushWriter{base, flusher, pusher}
+	case hijacker != nil && pusher != nil:
+		return hijackPushWriter{base, hijacker, pusher}
+	case flusher != nil:
+		return flushWriter{base, flusher}
+	case hijacker != nil:
+		return hijackWriter{base, hijacker}
+	case pusher != nil:
+		return pushWriter{base, pusher}
+	}
+	return w
+}
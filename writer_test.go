@@ -0,0 +1,122 @@
+package revel
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// A wrapper that upper-cases the body, and implements none of the optional
+// ResponseWriter interfaces.
+type upperWriter struct {
+	http.ResponseWriter
+	closed bool
+}
+
+func (w *upperWriter) Write(b []byte) (int, error) {
+	return w.ResponseWriter.Write(bytes.ToUpper(b))
+}
+
+func (w *upperWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestWrapWriter(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	resp := NewResponse(recorder)
+	var upper *upperWriter
+	resp.WrapWriter(func(w http.ResponseWriter) http.ResponseWriter {
+		upper = &upperWriter{ResponseWriter: w}
+		return upper
+	})
+
+	resp.WriteHeader(http.StatusOK, "text/plain")
+	resp.Out.Write([]byte("hello"))
+	flusher, ok := resp.Out.(http.Flusher)
+	if !ok {
+		t.Fatal("Expected the wrapped writer to remain a Flusher")
+	}
+	flusher.Flush()
+	if !recorder.Flushed || recorder.Body.String() != "HELLO" {
+		t.Errorf("Unexpected body %q, flushed %v", recorder.Body, recorder.Flushed)
+	}
+	if _, ok := resp.Out.(http.Hijacker); ok {
+		t.Errorf("Expected no Hijacker when neither writer can hijack")
+	}
+	if _, ok := resp.Out.(http.Pusher); ok {
+		t.Errorf("Expected no Pusher when neither writer can push")
+	}
+
+	if resp.Unwrap() != recorder {
+		t.Errorf("Expected Unwrap to return the original writer")
+	}
+	if err := resp.Close(); err != nil || !upper.closed {
+		t.Errorf("Expected the wrapper to be closed, got %v", err)
+	}
+	if resp.BytesWritten() != 5 {
+		t.Errorf("Expected 5 bytes written, got %d", resp.BytesWritten())
+	}
+}
+
+func TestCloseAssignedWriter(t *testing.T) {
+	resp := NewResponse(httptest.NewRecorder())
+	upper := &upperWriter{ResponseWriter: resp.Out}
+	resp.Out = upper
+	if err := resp.Close(); err != nil || !upper.closed {
+		t.Errorf("Expected a writer assigned to Out to be closed, got %v", err)
+	}
+
+	// Also beneath a wrapper.
+	resp = NewResponse(httptest.NewRecorder())
+	inner := &upperWriter{ResponseWriter: resp.Out}
+	resp.Out = inner
+	var outer *upperWriter
+	resp.WrapWriter(func(w http.ResponseWriter) http.ResponseWriter {
+		outer = &upperWriter{ResponseWriter: w}
+		return outer
+	})
+	if err := resp.Close(); err != nil || !inner.closed || !outer.closed {
+		t.Errorf("Expected both writers to be closed, got %v (inner %v, outer %v)", err, inner.closed, outer.closed)
+	}
+}
+
+// A wrapper that flushes itself, for a writer that can not.
+type flushingWriter struct {
+	http.ResponseWriter
+	flushed bool
+}
+
+func (w *flushingWriter) Flush() { w.flushed = true }
+
+func TestWrapWriterInterfaces(t *testing.T) {
+	resp := NewResponse(&readerFromWriter{header: make(http.Header)})
+	resp.WrapWriter(func(w http.ResponseWriter) http.ResponseWriter { return &upperWriter{ResponseWriter: w} })
+	if _, ok := resp.Out.(http.Flusher); ok {
+		t.Errorf("Expected no Flusher when neither writer can flush")
+	}
+
+	var flushing *flushingWriter
+	resp.WrapWriter(func(w http.ResponseWriter) http.ResponseWriter {
+		flushing = &flushingWriter{ResponseWriter: w}
+		return flushing
+	})
+	flusher, ok := resp.Out.(http.Flusher)
+	if !ok {
+		t.Fatal("Expected a Flusher when the wrapper can flush")
+	}
+	flusher.Flush()
+	if !flushing.flushed {
+		t.Errorf("Expected the wrapper to be flushed")
+	}
+
+	resp = NewResponse(&hijackableRecorder{httptest.NewRecorder(), nil})
+	resp.WrapWriter(func(w http.ResponseWriter) http.ResponseWriter { return &upperWriter{ResponseWriter: w} })
+	if _, ok := resp.Out.(http.Hijacker); !ok {
+		t.Errorf("Expected a Hijacker when the inner writer can hijack")
+	}
+	if err := resp.Close(); err != nil {
+		t.Errorf("Unexpected error closing: %v", err)
+	}
+}